@@ -0,0 +1,131 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// AIP193ErrorContainer renders a DefaultError in the JSON error format of Google APIs as described
+// in https://google.aip.dev/193, which is also produced by grpc-gateway:
+//
+//	{
+//	  "error": {
+//	    "code": 404,
+//	    "message": "The requested resource could not be found",
+//	    "status": "NOT_FOUND",
+//	    "details": [{"@type": "type.googleapis.com/google.rpc.ErrorInfo", "reason": "..."}]
+//	  }
+//	}
+//
+// The status and details are derived from DefaultError.GRPCStatus. The DebugInfo detail is only
+// included if Debug is true.
+type AIP193ErrorContainer struct {
+	Error *DefaultError
+
+	// Code is the HTTP status code of the response. If it is set and differs from the status code
+	// of the error, e.g. because it was forced using WriteErrorCode, the code and status are derived
+	// from it. The JSONWriter sets it to the written status code.
+	Code int
+
+	// Debug includes the DebugInfo detail. The JSONWriter sets it according to EnableDebug.
+	Debug bool
+}
+
+var (
+	_ json.Marshaler   = (*AIP193ErrorContainer)(nil)
+	_ json.Unmarshaler = (*AIP193ErrorContainer)(nil)
+)
+
+type aip193Error struct {
+	Code    int               `json:"code"`
+	Message string            `json:"message"`
+	Status  string            `json:"status"`
+	Details []json.RawMessage `json:"details,omitempty"`
+}
+
+// AIP193ErrorEnhancer is an ErrorEnhancer for the JSONWriter which renders errors according to AIP-193.
+func AIP193ErrorEnhancer(r *http.Request, err error) interface{} {
+	return &AIP193ErrorContainer{Error: ToDefaultError(err, r.Header.Get("X-Request-ID"))}
+}
+
+func (c *AIP193ErrorContainer) ID() string {
+	return c.Error.ID()
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c *AIP193ErrorContainer) MarshalJSON() ([]byte, error) {
+	s := c.Error.GRPCStatus().Proto()
+	body := aip193Error{
+		Code:    c.Error.StatusCode(),
+		Message: s.GetMessage(),
+		Status:  code.Code(s.GetCode()).String(),
+	}
+	if c.Code != 0 && c.Code != body.Code {
+		body.Code = c.Code
+		body.Status = code.Code(GRPCCodeFromHTTPStatus(c.Code)).String()
+	}
+	for _, d := range s.GetDetails() {
+		if !c.Debug && d.MessageIs((*errdetails.DebugInfo)(nil)) {
+			continue
+		}
+		b, err := protojson.Marshal(d)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		body.Details = append(body.Details, b)
+	}
+
+	return json.Marshal(struct {
+		Error aip193Error `json:"error"`
+	}{Error: body})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Details of unknown types are ignored. It fails
+// without modifying the container if the payload is not an AIP-193 error.
+func (c *AIP193ErrorContainer) UnmarshalJSON(b []byte) error {
+	var envelope struct {
+		Error *aip193Error `json:"error"`
+	}
+	if err := json.Unmarshal(b, &envelope); err != nil {
+		return errors.WithStack(err)
+	}
+	if envelope.Error == nil {
+		return errors.New("herodot: payload is not an AIP-193 error")
+	}
+	grpcCode, ok := code.Code_value[envelope.Error.Status]
+	if !ok || codes.Code(grpcCode) == codes.OK {
+		return errors.Errorf("herodot: unknown AIP-193 error status %q", envelope.Error.Status)
+	}
+
+	s := &spb.Status{Code: grpcCode, Message: envelope.Error.Message}
+	for _, raw := range envelope.Error.Details {
+		d := new(anypb.Any)
+		if err := protojson.Unmarshal(raw, d); err != nil {
+			continue
+		}
+		s.Details = append(s.Details, d)
+	}
+
+	if c.Error == nil {
+		c.Error = new(DefaultError)
+	}
+	c.Error.applyGRPCStatus(status.FromProto(s))
+	c.Code = envelope.Error.Code
+	if envelope.Error.Code != 0 {
+		c.Error.CodeField = envelope.Error.Code
+		c.Error.StatusField = http.StatusText(envelope.Error.Code)
+	}
+	return nil
+}
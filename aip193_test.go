@@ -0,0 +1,99 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestAIP193ErrorEnhancer(t *testing.T) {
+	newError := func() error {
+		return errors.WithStack(ErrNotFound().
+			WithID("user_not_found").
+			WithDomain("kratos.ory.sh").
+			WithDebug("sql: no rows").
+			WithRetryAfter(time.Second))
+	}
+
+	for _, tc := range []struct {
+		name  string
+		debug bool
+	}{
+		{name: "without debug"},
+		{name: "with debug", debug: true},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			h := NewJSONWriter(nil)
+			h.ErrorEnhancer = AIP193ErrorEnhancer
+			h.EnableDebug = tc.debug
+
+			rec := httptest.NewRecorder()
+			h.WriteError(rec, httptest.NewRequest("GET", "/", nil), newError())
+
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.Equal(t, "user_not_found", rec.Header().Get("Ory-Error-Id"))
+
+			var body struct {
+				Error struct {
+					Code    int                      `json:"code"`
+					Message string                   `json:"message"`
+					Status  string                   `json:"status"`
+					Details []map[string]interface{} `json:"details"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, http.StatusNotFound, body.Error.Code)
+			assert.Equal(t, "The requested resource could not be found", body.Error.Message)
+			assert.Equal(t, "NOT_FOUND", body.Error.Status)
+
+			types := map[string]map[string]interface{}{}
+			for _, d := range body.Error.Details {
+				types[d["@type"].(string)] = d
+			}
+			assert.Equal(t, "user_not_found", types["type.googleapis.com/google.rpc.ErrorInfo"]["reason"])
+			assert.Equal(t, "kratos.ory.sh", types["type.googleapis.com/google.rpc.ErrorInfo"]["domain"])
+			assert.Equal(t, "1s", types["type.googleapis.com/google.rpc.RetryInfo"]["retryDelay"])
+			if tc.debug {
+				assert.Equal(t, "sql: no rows", types["type.googleapis.com/google.rpc.DebugInfo"]["detail"])
+			} else {
+				assert.NotContains(t, types, "type.googleapis.com/google.rpc.DebugInfo")
+			}
+
+			t.Run("case=decode", func(t *testing.T) {
+				err := ErrorFromResponse(rec.Result())
+				var de *DefaultError
+				require.ErrorAs(t, err, &de)
+				assert.ErrorIs(t, err, ErrNotFound().WithID("user_not_found"))
+				assert.Equal(t, codes.NotFound, de.GRPCCode())
+				assert.Equal(t, "kratos.ory.sh", de.Domain())
+				assert.Equal(t, time.Second, de.RetryAfter())
+			})
+		})
+	}
+
+	t.Run("case=forced code", func(t *testing.T) {
+		h := NewJSONWriter(nil)
+		h.ErrorEnhancer = AIP193ErrorEnhancer
+
+		rec := httptest.NewRecorder()
+		h.WriteErrorCode(rec, httptest.NewRequest("GET", "/", nil), http.StatusServiceUnavailable, ErrNotFound())
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.JSONEq(t, `{"error":{"code":503,"message":"The requested resource could not be found","status":"UNAVAILABLE"}}`, rec.Body.String())
+	})
+
+	t.Run("case=not an AIP-193 error", func(t *testing.T) {
+		c := &AIP193ErrorContainer{Error: ErrNotFound()}
+		require.Error(t, json.Unmarshal([]byte(`{"error":{"code":404,"status":"Not Found","message":"foo"}}`), c))
+		assert.Equal(t, "The requested resource could not be found", c.Error.Error())
+	})
+}
@@ -0,0 +1,184 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog translates error messages.
+type Catalog interface {
+	// Languages returns the BCP 47 tags of the languages the catalog has translations for.
+	Languages() []string
+
+	// Translate returns the message with the given ID in the given language, rendered with args.
+	// It returns false if the catalog has no such message.
+	Translate(language, id string, args map[string]interface{}) (string, bool)
+}
+
+// MessageCarrier can be implemented by an error to support translating its message.
+type MessageCarrier interface {
+	// MessageID returns the ID of the error message in a Catalog, if applicable.
+	MessageID() string
+
+	// MessageArgs returns the arguments of the error message template, if applicable.
+	MessageArgs() map[string]interface{}
+}
+
+// messageOf returns the message ID and arguments of the first carrier in the error's chain
+// which has a message ID.
+func messageOf(err error) (id string, args map[string]interface{}) {
+	walkErrors(err, func(err error) {
+		if c, ok := err.(MessageCarrier); ok && id == "" {
+			id, args = c.MessageID(), c.MessageArgs()
+		}
+	})
+	return
+}
+
+// MessageID returns the ID of the error message in a Catalog.
+func (e *DefaultError) MessageID() string {
+	return e.messageID
+}
+
+// MessageArgs returns the arguments of the error message template.
+func (e *DefaultError) MessageArgs() map[string]interface{} {
+	return e.messageArgs
+}
+
+// WithMessageID sets the ID of the error message in a Catalog. The error's message is used if
+// the catalog has no translation. Mutates and returns the receiver.
+func (e *DefaultError) WithMessageID(id string) *DefaultError {
+	e.messageID = id
+	return e
+}
+
+// WithMessageArg adds an argument of the error message template. Mutates and returns the receiver.
+func (e *DefaultError) WithMessageArg(key string, value interface{}) *DefaultError {
+	if e.messageArgs == nil {
+		e.messageArgs = map[string]interface{}{}
+	} else {
+		e.messageArgs = maps.Clone(e.messageArgs)
+	}
+	e.messageArgs[key] = value
+	return e
+}
+
+// MessageCatalog is a Catalog whose messages are text/template templates, e.g.
+// `User {{.user}} does not exist`, which are executed with the message arguments.
+// It is safe for concurrent use.
+type MessageCatalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]*template.Template
+	tags     map[string]string
+}
+
+var _ Catalog = (*MessageCatalog)(nil)
+
+// NewMessageCatalog returns an empty MessageCatalog.
+func NewMessageCatalog() *MessageCatalog {
+	return &MessageCatalog{
+		messages: map[string]map[string]*template.Template{},
+		tags:     map[string]string{},
+	}
+}
+
+// LoadMessageCatalog loads a MessageCatalog from the JSON and YAML files in the root of fsys,
+// e.g. an embed.FS or os.DirFS. Each file contains the messages of the language it is named
+// after, e.g. `de.yaml` or `pt-BR.json`, as an object mapping message IDs to message templates.
+func LoadMessageCatalog(fsys fs.FS) (*MessageCatalog, error) {
+	c := NewMessageCatalog()
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		ext := path.Ext(name)
+		var unmarshal func([]byte, interface{}) error
+		switch ext {
+		case ".json":
+			unmarshal = json.Unmarshal
+		case ".yaml", ".yml":
+			unmarshal = yaml.Unmarshal
+		default:
+			continue
+		}
+
+		raw, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		var messages map[string]string
+		if err := unmarshal(raw, &messages); err != nil {
+			return nil, errors.Wrapf(err, "unable to decode message catalog %s", name)
+		}
+		if err := c.Add(strings.TrimSuffix(name, ext), messages); err != nil {
+			return nil, errors.WithMessagef(err, "unable to load message catalog %s", name)
+		}
+	}
+
+	return c, nil
+}
+
+// Add adds the messages of a language, mapping message IDs to message templates.
+func (c *MessageCatalog) Add(language string, messages map[string]string) error {
+	parsed := make(map[string]*template.Template, len(messages))
+	for id, message := range messages {
+		t, err := template.New(id).Option("missingkey=zero").Parse(message)
+		if err != nil {
+			return errors.Wrapf(err, "unable to parse message %s", id)
+		}
+		parsed[id] = t
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := strings.ToLower(language)
+	if c.messages[key] == nil {
+		c.messages[key] = map[string]*template.Template{}
+		c.tags[key] = language
+	}
+	maps.Copy(c.messages[key], parsed)
+	return nil
+}
+
+// Languages implements Catalog.
+func (c *MessageCatalog) Languages() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Sorted(maps.Values(c.tags))
+}
+
+// Translate implements Catalog.
+func (c *MessageCatalog) Translate(language, id string, args map[string]interface{}) (string, bool) {
+	c.mu.RLock()
+	t, ok := c.messages[strings.ToLower(language)][id]
+	c.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+
+	var b bytes.Buffer
+	if err := t.Execute(&b, args); err != nil {
+		return "", false
+	}
+	return b.String(), true
+}
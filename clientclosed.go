@@ -3,6 +3,8 @@
 
 package herodot
 
+import "net/http"
+
 // StatusClientClosedRequest (reported as 499 Client Closed Request) is a faux
 // but de-facto standard HTTP status code first used by nginx, indicating the
 // client canceled the request. Because the client canceled, it is never
@@ -11,3 +13,11 @@ package herodot
 //
 // http://nginx.org/en/docs/dev/development_guide.html
 const StatusClientClosedRequest int = 499
+
+// statusText is like http.StatusText but also knows StatusClientClosedRequest.
+func statusText(code int) string {
+	if code == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(code)
+}
@@ -0,0 +1,176 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DebugTokenHeader is the default header of debug tokens verified by HMACDebugPolicy.
+const DebugTokenHeader = "Ory-Debug-Token"
+
+// DebugPolicy decides whether debug information, such as DefaultError.Debug and stack
+// traces, is exposed in the response to a request. Writers expose debug information if
+// either EnableDebug is set or their DebugPolicy enables it for the request.
+type DebugPolicy interface {
+	// EnableDebug returns true if debug information may be exposed in the response to r.
+	EnableDebug(r *http.Request) bool
+}
+
+// DebugPolicyFunc is a function implementing DebugPolicy.
+type DebugPolicyFunc func(r *http.Request) bool
+
+// EnableDebug implements DebugPolicy.
+func (f DebugPolicyFunc) EnableDebug(r *http.Request) bool {
+	return f(r)
+}
+
+// debugEnabled returns true if debug information may be exposed in the response to r.
+func debugEnabled(r *http.Request, enabled bool, policy DebugPolicy) bool {
+	return enabled || (policy != nil && policy.EnableDebug(r))
+}
+
+// AnyDebugPolicy enables debug information if any of the policies enables it.
+func AnyDebugPolicy(policies ...DebugPolicy) DebugPolicy {
+	return DebugPolicyFunc(func(r *http.Request) bool {
+		for _, p := range policies {
+			if p.EnableDebug(r) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+type debugContextKey struct{}
+
+// ContextWithDebug returns a context which enables debug information when used with
+// ContextDebugPolicy. Use it in middleware, e.g. after authenticating an operator.
+func ContextWithDebug(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, debugContextKey{}, enabled)
+}
+
+// DebugFromContext returns the value set using ContextWithDebug.
+func DebugFromContext(ctx context.Context) bool {
+	enabled, _ := ctx.Value(debugContextKey{}).(bool)
+	return enabled
+}
+
+// ContextDebugPolicy enables debug information for requests whose context was created
+// using ContextWithDebug.
+func ContextDebugPolicy() DebugPolicy {
+	return DebugPolicyFunc(func(r *http.Request) bool {
+		return DebugFromContext(r.Context())
+	})
+}
+
+// TrustedNetworkDebugPolicy enables debug information for requests from trusted networks.
+// It uses the address of the immediate peer (http.Request.RemoteAddr), not forwarding
+// headers, which clients can spoof.
+type TrustedNetworkDebugPolicy struct {
+	Networks []netip.Prefix
+}
+
+var _ DebugPolicy = (*TrustedNetworkDebugPolicy)(nil)
+
+// NewTrustedNetworkDebugPolicy returns a policy trusting the given networks in CIDR notation.
+func NewTrustedNetworkDebugPolicy(cidrs ...string) (*TrustedNetworkDebugPolicy, error) {
+	p := &TrustedNetworkDebugPolicy{Networks: make([]netip.Prefix, len(cidrs))}
+	for i, cidr := range cidrs {
+		n, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+		p.Networks[i] = n.Masked()
+	}
+	return p, nil
+}
+
+// EnableDebug implements DebugPolicy.
+func (p *TrustedNetworkDebugPolicy) EnableDebug(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, n := range p.Networks {
+		if n.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// HMACDebugPolicy enables debug information for requests carrying a valid debug token, as
+// issued by NewDebugToken, in the Header header.
+type HMACDebugPolicy struct {
+	// Keys verify debug tokens. The first key signs new tokens, the remaining keys
+	// allow rotating keys without invalidating issued tokens.
+	Keys [][]byte
+
+	// Header is the request header carrying the debug token. Defaults to DebugTokenHeader.
+	Header string
+}
+
+var _ DebugPolicy = (*HMACDebugPolicy)(nil)
+
+// NewHMACDebugPolicy returns a policy verifying debug tokens using the given keys.
+func NewHMACDebugPolicy(keys ...[]byte) *HMACDebugPolicy {
+	return &HMACDebugPolicy{Keys: keys, Header: DebugTokenHeader}
+}
+
+// NewDebugToken returns a debug token which is valid until expiresAt.
+func (p *HMACDebugPolicy) NewDebugToken(expiresAt time.Time) string {
+	if len(p.Keys) == 0 {
+		return ""
+	}
+	payload := strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(debugTokenMAC(p.Keys[0], payload))
+}
+
+// EnableDebug implements DebugPolicy.
+func (p *HMACDebugPolicy) EnableDebug(r *http.Request) bool {
+	header := p.Header
+	if header == "" {
+		header = DebugTokenHeader
+	}
+
+	payload, sig, ok := strings.Cut(r.Header.Get(header), ".")
+	if !ok {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	for _, key := range p.Keys {
+		if hmac.Equal(mac, debugTokenMAC(key, payload)) {
+			return true
+		}
+	}
+	return false
+}
+
+func debugTokenMAC(key []byte, payload string) []byte {
+	m := hmac.New(sha256.New, key)
+	_, _ = m.Write([]byte("herodot-debug:" + payload))
+	return m.Sum(nil)
+}
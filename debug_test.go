@@ -0,0 +1,163 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugPolicies(t *testing.T) {
+	hmacPolicy := NewHMACDebugPolicy([]byte("new-key"), []byte("old-key"))
+	oldPolicy := NewHMACDebugPolicy([]byte("old-key"))
+	otherPolicy := NewHMACDebugPolicy([]byte("other-key"))
+	networkPolicy, err := NewTrustedNetworkDebugPolicy("10.0.0.0/8", "::1/128")
+	require.NoError(t, err)
+
+	_, err = NewTrustedNetworkDebugPolicy("not-a-network")
+	assert.Error(t, err)
+
+	for k, tc := range []struct {
+		policy DebugPolicy
+		modify func(r *http.Request) *http.Request
+		expect bool
+	}{
+		{
+			policy: hmacPolicy,
+			modify: func(r *http.Request) *http.Request {
+				r.Header.Set(DebugTokenHeader, hmacPolicy.NewDebugToken(time.Now().Add(time.Minute)))
+				return r
+			},
+			expect: true,
+		},
+		{
+			policy: hmacPolicy,
+			modify: func(r *http.Request) *http.Request {
+				r.Header.Set(DebugTokenHeader, oldPolicy.NewDebugToken(time.Now().Add(time.Minute)))
+				return r
+			},
+			expect: true,
+		},
+		{
+			policy: hmacPolicy,
+			modify: func(r *http.Request) *http.Request {
+				r.Header.Set(DebugTokenHeader, otherPolicy.NewDebugToken(time.Now().Add(time.Minute)))
+				return r
+			},
+		},
+		{
+			policy: hmacPolicy,
+			modify: func(r *http.Request) *http.Request {
+				r.Header.Set(DebugTokenHeader, hmacPolicy.NewDebugToken(time.Now().Add(-time.Minute)))
+				return r
+			},
+		},
+		{
+			policy: hmacPolicy,
+			modify: func(r *http.Request) *http.Request {
+				r.Header.Set(DebugTokenHeader, fmt.Sprintf("%d.bogus", time.Now().Add(time.Hour).Unix()))
+				return r
+			},
+		},
+		{
+			policy: hmacPolicy,
+			modify: func(r *http.Request) *http.Request { return r },
+		},
+		{
+			policy: networkPolicy,
+			modify: func(r *http.Request) *http.Request {
+				r.RemoteAddr = "10.1.2.3:1234"
+				return r
+			},
+			expect: true,
+		},
+		{
+			policy: networkPolicy,
+			modify: func(r *http.Request) *http.Request {
+				r.RemoteAddr = "[::1]:1234"
+				return r
+			},
+			expect: true,
+		},
+		{
+			policy: networkPolicy,
+			modify: func(r *http.Request) *http.Request {
+				r.RemoteAddr = "192.168.1.1:1234"
+				r.Header.Set("X-Forwarded-For", "10.1.2.3")
+				return r
+			},
+		},
+		{
+			policy: ContextDebugPolicy(),
+			modify: func(r *http.Request) *http.Request {
+				return r.WithContext(ContextWithDebug(r.Context(), true))
+			},
+			expect: true,
+		},
+		{
+			policy: ContextDebugPolicy(),
+			modify: func(r *http.Request) *http.Request { return r },
+		},
+		{
+			policy: AnyDebugPolicy(hmacPolicy, ContextDebugPolicy()),
+			modify: func(r *http.Request) *http.Request {
+				return r.WithContext(ContextWithDebug(r.Context(), true))
+			},
+			expect: true,
+		},
+		{
+			policy: AnyDebugPolicy(hmacPolicy, networkPolicy),
+			modify: func(r *http.Request) *http.Request { return r },
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			r := tc.modify(httptest.NewRequest("GET", "/", nil))
+			assert.Equal(t, tc.expect, tc.policy.EnableDebug(r))
+		})
+	}
+}
+
+func TestWritersDebugPolicy(t *testing.T) {
+	policy := ContextDebugPolicy()
+	err := errors.WithStack(ErrInternalServerError().WithDebug("connection refused"))
+
+	jw := NewJSONWriter(nil)
+	jw.DebugPolicy = policy
+	tw := NewTextWriter(nil, "plain")
+	tw.DebugPolicy = policy
+
+	for _, debug := range []bool{false, true} {
+		t.Run(fmt.Sprintf("debug=%v", debug), func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r = r.WithContext(ContextWithDebug(r.Context(), debug))
+
+			rec := httptest.NewRecorder()
+			jw.WriteError(rec, r, err)
+			var ec ErrorContainer
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&ec))
+			if debug {
+				assert.Equal(t, "connection refused", ec.Error.Debug())
+			} else {
+				assert.Empty(t, ec.Error.Debug())
+			}
+
+			rec = httptest.NewRecorder()
+			tw.WriteError(rec, r, err)
+			if debug {
+				assert.Contains(t, rec.Body.String(), "debug=connection refused")
+				assert.Contains(t, rec.Body.String(), "TestWritersDebugPolicy")
+			} else {
+				assert.Equal(t, ErrInternalServerError().Error(), rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+const (
+	// DefaultMaxStackDepth is the default number of stack frames added to error payloads.
+	DefaultMaxStackDepth = 32
+
+	// DefaultMaxCauses is the default number of causes added to error payloads.
+	DefaultMaxCauses = 16
+)
+
+// StackFrame is a frame of the stack trace of an error.
+type StackFrame struct {
+	// The fully qualified function name
+	//
+	// example: github.com/ory/herodot.TestFunction
+	Function string `json:"function"`
+
+	// The source file
+	//
+	// example: herodot/error_test.go
+	File string `json:"file"`
+
+	// The line in the source file
+	//
+	// example: 42
+	Line int `json:"line"`
+}
+
+// ErrorCause is a layer of the chain of wrapped errors.
+type ErrorCause struct {
+	// The error message of the layer
+	Message string `json:"message"`
+
+	// The Go type of the layer
+	//
+	// example: *errors.withStack
+	Type string `json:"type"`
+}
+
+// DebugTraceOptions configure the stack trace and cause chain which writers add to error
+// payloads when debug output is enabled.
+type DebugTraceOptions struct {
+	// MaxStackDepth limits the number of stack frames. Zero uses DefaultMaxStackDepth,
+	// a negative value omits the stack trace.
+	MaxStackDepth int
+
+	// MaxCauses limits the number of causes. Zero uses DefaultMaxCauses, a negative
+	// value omits the causes.
+	MaxCauses int
+
+	// TrimPathPrefixes are removed from the file paths of stack frames, e.g. to hide
+	// the GOPATH or the build directory. The first matching prefix is removed.
+	TrimPathPrefixes []string
+}
+
+func (o DebugTraceOptions) trimPath(file string) string {
+	for _, prefix := range o.TrimPathPrefixes {
+		if strings.HasPrefix(file, prefix) {
+			return strings.TrimPrefix(file, prefix)
+		}
+	}
+	return file
+}
+
+// Stack returns the stack trace.
+func (e *DefaultError) Stack() []StackFrame {
+	return e.StackField
+}
+
+// Causes returns the chain of wrapped errors.
+func (e *DefaultError) Causes() []ErrorCause {
+	return e.CausesField
+}
+
+// WithDebugTrace sets the stack trace and the chain of wrapped errors from the error
+// wrapped using Wrap or WithTrace. Mutates and returns the receiver.
+func (e *DefaultError) WithDebugTrace(o DebugTraceOptions) *DefaultError {
+	e.StackField, e.CausesField = nil, nil
+
+	maxDepth := o.MaxStackDepth
+	if maxDepth == 0 {
+		maxDepth = DefaultMaxStackDepth
+	}
+	for _, f := range e.StackTrace() {
+		if len(e.StackField) >= maxDepth {
+			break
+		}
+		pc := uintptr(f) - 1
+		fn := runtime.FuncForPC(pc)
+		if fn == nil {
+			continue
+		}
+		file, line := fn.FileLine(pc)
+		e.StackField = append(e.StackField, StackFrame{
+			Function: fn.Name(),
+			File:     o.trimPath(file),
+			Line:     line,
+		})
+	}
+
+	maxCauses := o.MaxCauses
+	if maxCauses == 0 {
+		maxCauses = DefaultMaxCauses
+	}
+	if maxCauses > 0 && e.err != nil && e.err != e {
+		walkErrors(e.err, func(err error) {
+			if len(e.CausesField) < maxCauses {
+				e.CausesField = append(e.CausesField, ErrorCause{Message: err.Error(), Type: fmt.Sprintf("%T", err)})
+			}
+		})
+	}
+
+	return e
+}
+
+// debugError returns a copy of de, which only contains debug information if debug is true.
+func debugError(de *DefaultError, debug bool, o DebugTraceOptions) *DefaultError {
+	de = de.Clone()
+	if !debug {
+		de.DebugField = ""
+		de.StackField = nil
+		de.CausesField = nil
+		return de
+	}
+	return de.WithDebugTrace(o)
+}
@@ -0,0 +1,91 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"encoding/json"
+	stderr "errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDebugTrace(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	cause := stderr.New("connection refused")
+	de := ErrInternalServerError().WithWrap(fmt.Errorf("could not query: %w", errors.WithStack(cause)))
+
+	t.Run("case=defaults", func(t *testing.T) {
+		de := de.Clone().WithDebugTrace(DebugTraceOptions{})
+		require.NotEmpty(t, de.Stack())
+		assert.Equal(t, "github.com/ory/herodot.TestWithDebugTrace", de.Stack()[0].Function)
+		assert.Equal(t, wd+"/debug_trace_test.go", de.Stack()[0].File)
+		assert.NotZero(t, de.Stack()[0].Line)
+		assert.Equal(t, []ErrorCause{
+			{Message: "could not query: connection refused", Type: "*fmt.wrapError"},
+			{Message: "connection refused", Type: "*errors.withStack"},
+			{Message: "connection refused", Type: "*errors.errorString"},
+		}, de.Causes())
+	})
+
+	t.Run("case=limits and trimming", func(t *testing.T) {
+		de := de.Clone().WithDebugTrace(DebugTraceOptions{
+			MaxStackDepth:    1,
+			MaxCauses:        2,
+			TrimPathPrefixes: []string{"/does/not/match/", wd + "/"},
+		})
+		assert.Equal(t, []StackFrame{{Function: "github.com/ory/herodot.TestWithDebugTrace", File: "debug_trace_test.go", Line: de.Stack()[0].Line}}, de.Stack())
+		assert.Len(t, de.Causes(), 2)
+	})
+
+	t.Run("case=omitted", func(t *testing.T) {
+		de := de.Clone().WithDebugTrace(DebugTraceOptions{MaxStackDepth: -1, MaxCauses: -1})
+		assert.Empty(t, de.Stack())
+		assert.Empty(t, de.Causes())
+	})
+
+	t.Run("case=no wrapped error", func(t *testing.T) {
+		de := ErrNotFound().WithDebugTrace(DebugTraceOptions{})
+		assert.Empty(t, de.Stack())
+		assert.Empty(t, de.Causes())
+	})
+}
+
+func TestJSONWriterDebugTrace(t *testing.T) {
+	err := errors.WithStack(ErrNotFound().WithDebug("sql: no rows"))
+
+	for _, debug := range []bool{false, true} {
+		t.Run(fmt.Sprintf("debug=%v", debug), func(t *testing.T) {
+			h := NewJSONWriter(nil)
+			h.EnableDebug = debug
+			h.DebugTrace = DebugTraceOptions{MaxStackDepth: 1}
+
+			rec := httptest.NewRecorder()
+			h.WriteError(rec, httptest.NewRequest("GET", "/", nil), err)
+
+			var ec ErrorContainer
+			require.NoError(t, json.NewDecoder(strings.NewReader(rec.Body.String())).Decode(&ec))
+			if !debug {
+				assert.NotContains(t, rec.Body.String(), `"stack"`)
+				assert.NotContains(t, rec.Body.String(), `"causes"`)
+				return
+			}
+
+			require.Len(t, ec.Error.Stack(), 1)
+			assert.Equal(t, "github.com/ory/herodot.TestJSONWriterDebugTrace", ec.Error.Stack()[0].Function)
+			assert.Equal(t, []ErrorCause{
+				{Message: "The requested resource could not be found", Type: "*errors.withStack"},
+				{Message: "The requested resource could not be found", Type: "*herodot.DefaultError"},
+			}, ec.Error.Causes())
+		})
+	}
+}
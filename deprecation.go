@@ -0,0 +1,125 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Deprecation describes the deprecation of a resource. Writers send it as the Deprecation
+// (RFC 9745), Sunset (RFC 8594) and Link headers for requests marked using WithDeprecation
+// or DeprecationMiddleware.
+type Deprecation struct {
+	// Since is the time the resource was or will be deprecated. RFC 9745 requires it, so
+	// a zero Since is sent as the Unix epoch, i.e. the resource is deprecated already.
+	Since time.Time
+
+	// Sunset, if set, is the time the resource will become unavailable.
+	Sunset time.Time
+
+	// Link, if set, links to documentation about the deprecation, e.g. a migration guide.
+	Link string
+
+	// Warning, if set, is added to the warnings of errors written for the request.
+	Warning string
+}
+
+// DeprecationReporter can be implemented by an ErrorReporter to be notified when a response
+// is written for a deprecated resource, e.g. to count callers still using it.
+type DeprecationReporter interface {
+	ReportDeprecation(r *http.Request, d Deprecation)
+}
+
+type deprecationContextKey struct{}
+
+// WithDeprecation marks the request as accessing a deprecated resource. Use it for individual
+// Write* calls, e.g. `h.Write(w, herodot.WithDeprecation(r, d), e)`, or DeprecationMiddleware
+// for whole routes.
+func WithDeprecation(r *http.Request, d Deprecation) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), deprecationContextKey{}, &d))
+}
+
+// DeprecationFromContext returns the deprecation set using WithDeprecation.
+func DeprecationFromContext(ctx context.Context) (Deprecation, bool) {
+	d, ok := ctx.Value(deprecationContextKey{}).(*Deprecation)
+	if !ok {
+		return Deprecation{}, false
+	}
+	return *d, true
+}
+
+// DeprecationMiddleware marks all requests to the handler as accessing a deprecated resource.
+func DeprecationMiddleware(d Deprecation) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, WithDeprecation(r, d))
+		})
+	}
+}
+
+// setDeprecationHeaders sets the deprecation headers if the request is marked as deprecated and
+// notifies the reporter if it implements DeprecationReporter.
+func setDeprecationHeaders(h http.Header, r *http.Request, reporter ErrorReporter) {
+	d, ok := DeprecationFromContext(r.Context())
+	if !ok {
+		return
+	}
+
+	since := d.Since
+	if since.IsZero() {
+		since = time.Unix(0, 0)
+	}
+	h.Set("Deprecation", "@"+strconv.FormatInt(since.Unix(), 10))
+	if !d.Sunset.IsZero() {
+		h.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		h.Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"`, d.Link))
+	}
+
+	if dr, ok := reporter.(DeprecationReporter); ok {
+		dr.ReportDeprecation(r, d)
+	}
+}
+
+// withDeprecationWarning returns the warnings including the warning of the request's deprecation.
+// The writers add it right before rendering so that the error is not converted early.
+func withDeprecationWarning(r *http.Request, warnings []string) []string {
+	if d, ok := DeprecationFromContext(r.Context()); ok && d.Warning != "" && !slices.Contains(warnings, d.Warning) {
+		return append(slices.Clone(warnings), d.Warning)
+	}
+	return warnings
+}
+
+// WarningsCarrier can be implemented by an error to support error contexts.
+type WarningsCarrier interface {
+	// Warnings returns warnings for the client, if applicable.
+	Warnings() []string
+}
+
+func (e *DefaultError) Warnings() []string {
+	return e.WarningsField
+}
+
+// WithWarning adds a warning for the client, e.g. about the use of a deprecated resource.
+// Mutates and returns the receiver.
+func (e *DefaultError) WithWarning(warning string) *DefaultError {
+	e.WarningsField = append(slices.Clone(e.WarningsField), warning)
+	return e
+}
+
+// warningsOf returns the warnings of the first carrier in the error's chain which has any.
+func warningsOf(err error) (warnings []string) {
+	walkErrors(err, func(err error) {
+		if c, ok := err.(WarningsCarrier); ok && len(warnings) == 0 {
+			warnings = slices.Clone(c.Warnings())
+		}
+	})
+	return
+}
@@ -0,0 +1,115 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type deprecationReporter struct {
+	stdReporter
+	reported []Deprecation
+}
+
+func (r *deprecationReporter) ReportDeprecation(_ *http.Request, d Deprecation) {
+	r.reported = append(r.reported, d)
+}
+
+func TestDeprecation(t *testing.T) {
+	d := Deprecation{
+		Since:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:  time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC),
+		Link:    "https://example.com/deprecation",
+		Warning: "This endpoint is deprecated, use /v2/users instead",
+	}
+
+	assertHeaders := func(t *testing.T, h http.Header) {
+		assert.Equal(t, "@1767225600", h.Get("Deprecation"))
+		assert.Equal(t, "Thu, 31 Dec 2026 23:59:59 GMT", h.Get("Sunset"))
+		assert.Equal(t, `<https://example.com/deprecation>; rel="deprecation"`, h.Get("Link"))
+	}
+
+	for name, w := range map[string]func(ErrorReporter) Writer{
+		"json":    func(r ErrorReporter) Writer { return NewJSONWriter(r) },
+		"text":    func(r ErrorReporter) Writer { return NewTextWriter(r, "plain") },
+		"problem": func(r ErrorReporter) Writer { return NewProblemWriter(r) },
+		"html":    func(r ErrorReporter) Writer { return NewHTMLWriter(r) },
+	} {
+		t.Run("writer="+name, func(t *testing.T) {
+			reporter := new(deprecationReporter)
+			h := w(reporter)
+
+			rec := httptest.NewRecorder()
+			h.Write(rec, WithDeprecation(httptest.NewRequest("GET", "/", nil), d), map[string]string{"foo": "bar"})
+			assertHeaders(t, rec.Header())
+
+			rec = httptest.NewRecorder()
+			h.WriteError(rec, WithDeprecation(httptest.NewRequest("GET", "/", nil), d), ErrNotFound())
+			assertHeaders(t, rec.Header())
+			assert.Contains(t, rec.Body.String(), d.Warning)
+
+			assert.Equal(t, []Deprecation{d, d}, reporter.reported)
+
+			rec = httptest.NewRecorder()
+			h.Write(rec, httptest.NewRequest("GET", "/", nil), map[string]string{"foo": "bar"})
+			assert.Empty(t, rec.Header().Get("Deprecation"))
+			assert.Len(t, reporter.reported, 2)
+		})
+	}
+
+	t.Run("case=middleware", func(t *testing.T) {
+		reporter := new(deprecationReporter)
+		h := NewJSONWriter(reporter)
+		ts := httptest.NewServer(DeprecationMiddleware(d)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.WriteError(w, r, ErrNotFound().WithWarning("Another warning"))
+		})))
+		t.Cleanup(ts.Close)
+
+		res, err := ts.Client().Get(ts.URL)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assertHeaders(t, res.Header)
+		assert.Len(t, reporter.reported, 1)
+
+		var ec ErrorContainer
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&ec))
+		assert.Equal(t, []string{"Another warning", d.Warning}, ec.Error.Warnings())
+	})
+
+	t.Run("case=plain error", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("X-Request-ID", "request-id")
+
+		rec := httptest.NewRecorder()
+		NewJSONWriter(nil).WriteError(rec, WithDeprecation(r, d), errors.New("plain error"))
+
+		var ec ErrorContainer
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&ec))
+		assert.Equal(t, "request-id", ec.Error.RequestID())
+		assert.Equal(t, []string{d.Warning}, ec.Error.Warnings())
+	})
+
+	t.Run("case=zero since", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewJSONWriter(nil).Write(rec, WithDeprecation(httptest.NewRequest("GET", "/", nil), Deprecation{Link: d.Link}), "foo")
+		assert.Equal(t, "@0", rec.Header().Get("Deprecation"))
+	})
+
+	t.Run("case=problem warnings", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewProblemWriter(nil).WriteError(rec, WithDeprecation(httptest.NewRequest("GET", "/", nil), d), ErrNotFound())
+
+		var p Problem
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&p))
+		assert.Equal(t, []string{d.Warning}, p.Warnings)
+	})
+}
@@ -4,11 +4,14 @@
 package herodot
 
 import (
+	"encoding/json"
 	stderr "errors"
 	"fmt"
 	"io"
 	"maps"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/pkg/errors"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
@@ -31,6 +34,18 @@ type noCopy struct{}
 func (*noCopy) Lock()   {}
 func (*noCopy) Unlock() {}
 
+// FieldViolation describes a single field of a request which failed validation.
+type FieldViolation struct {
+	// The path of the field, e.g. `user.email`.
+	Field string `json:"field"`
+
+	// A human-readable description of why the field is invalid.
+	Description string `json:"description"`
+
+	// A machine-readable reason, e.g. the ID of the validation rule which failed.
+	Reason string `json:"reason,omitempty"`
+}
+
 // DefaultError is not safe to shallow copy because it contains
 // fields which are not value types, e.g. maps.
 // A shallow copy would inadvertently share these underlying fields,
@@ -84,6 +99,58 @@ type DefaultError struct {
 	// Further error details
 	DetailsField map[string]interface{} `json:"details,omitempty"`
 
+	// The error domain
+	//
+	// The logical grouping to which the error ID belongs, typically the name of the service.
+	//
+	// example: kratos.ory.sh
+	DomainField string `json:"domain,omitempty"`
+
+	// Field violations
+	//
+	// The fields of the request which failed validation.
+	FieldViolationsField []FieldViolation `json:"field_violations,omitempty"`
+
+	// Precondition violations
+	//
+	// The preconditions which failed, e.g. terms of service which were not accepted.
+	PreconditionViolationsField []PreconditionViolation `json:"precondition_violations,omitempty"`
+
+	// Quota violations
+	//
+	// The quotas which were exceeded.
+	QuotaViolationsField []QuotaViolation `json:"quota_violations,omitempty"`
+
+	// Resource information
+	//
+	// The resource which is being accessed.
+	ResourceInfoField *ResourceInfo `json:"resource_info,omitempty"`
+
+	// Help links
+	//
+	// Links to documentation about the error.
+	HelpLinksField []HelpLink `json:"help_links,omitempty"`
+
+	// Localized message
+	//
+	// An error message which is safe to show to the end user.
+	LocalizedMessageField *LocalizedMessage `json:"localized_message,omitempty"`
+
+	// Warnings
+	//
+	// Warnings for the client, e.g. about the use of a deprecated resource.
+	WarningsField []string `json:"warnings,omitempty"`
+
+	// Stack trace
+	//
+	// Only exposed if debug output is enabled.
+	StackField []StackFrame `json:"stack,omitempty"`
+
+	// Causes
+	//
+	// The chain of wrapped errors. Only exposed if debug output is enabled.
+	CausesField []ErrorCause `json:"causes,omitempty"`
+
 	// Error message
 	//
 	// The error's message.
@@ -94,6 +161,27 @@ type DefaultError struct {
 
 	GRPCCodeField codes.Code `json:"-"`
 	err           error
+
+	retryAfter time.Duration
+	rateLimit  *RateLimit
+	headers    http.Header
+
+	messageID   string
+	messageArgs map[string]interface{}
+	localized   bool
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The gRPC code, which is not part
+// of the JSON representation, is derived from the status code.
+func (e *DefaultError) UnmarshalJSON(b []byte) error {
+	type defaultError DefaultError
+	if err := json.Unmarshal(b, (*defaultError)(e)); err != nil {
+		return err
+	}
+	if e.GRPCCodeField == codes.OK && e.CodeField != 0 {
+		e.GRPCCodeField = GRPCCodeFromHTTPStatus(e.CodeField)
+	}
+	return nil
 }
 
 func (e *DefaultError) Clone() *DefaultError {
@@ -104,11 +192,27 @@ func (e *DefaultError) Clone() *DefaultError {
 		RIDField:    e.RIDField,
 		ReasonField: e.ReasonField,
 		DebugField:  e.DebugField,
+		DomainField: e.DomainField,
 		// Fingers crossed that the values in the map are safe to shallow copy.
-		DetailsField:  maps.Clone(e.DetailsField),
-		ErrorField:    e.ErrorField,
-		GRPCCodeField: e.GRPCCodeField,
-		err:           e.err,
+		DetailsField:                maps.Clone(e.DetailsField),
+		FieldViolationsField:        slices.Clone(e.FieldViolationsField),
+		PreconditionViolationsField: slices.Clone(e.PreconditionViolationsField),
+		QuotaViolationsField:        slices.Clone(e.QuotaViolationsField),
+		ResourceInfoField:           clonePtr(e.ResourceInfoField),
+		HelpLinksField:              slices.Clone(e.HelpLinksField),
+		LocalizedMessageField:       clonePtr(e.LocalizedMessageField),
+		WarningsField:               slices.Clone(e.WarningsField),
+		StackField:                  slices.Clone(e.StackField),
+		CausesField:                 slices.Clone(e.CausesField),
+		ErrorField:                  e.ErrorField,
+		GRPCCodeField:               e.GRPCCodeField,
+		err:                         e.err,
+		retryAfter:                  e.retryAfter,
+		rateLimit:                   e.rateLimit.clone(),
+		headers:                     e.headers.Clone(),
+		messageID:                   e.messageID,
+		messageArgs:                 maps.Clone(e.messageArgs),
+		localized:                   e.localized,
 	}
 	return res
 }
@@ -196,12 +300,38 @@ func (e *DefaultError) Details() map[string]interface{} {
 	return e.DetailsField
 }
 
+func (e *DefaultError) Domain() string {
+	return e.DomainField
+}
+
+// WithDomain sets the error domain. Mutates and returns the receiver.
+func (e *DefaultError) WithDomain(domain string) *DefaultError {
+	e.DomainField = domain
+	return e
+}
+
+// StatusCode returns the HTTP status code. If it is not set, it is derived from the gRPC code.
 func (e *DefaultError) StatusCode() int {
+	if e.CodeField == 0 && e.GRPCCodeField != codes.OK {
+		return HTTPStatusFromGRPCCode(e.GRPCCodeField)
+	}
 	return e.CodeField
 }
 
+// GRPCCode returns the gRPC code. If it is not set, it is derived from the HTTP status code.
+// Because an error never has the code codes.OK, codes.Unknown is returned if neither is set.
+func (e *DefaultError) GRPCCode() codes.Code {
+	if e.GRPCCodeField != codes.OK {
+		return e.GRPCCodeField
+	}
+	if c := GRPCCodeFromHTTPStatus(e.CodeField); c != codes.OK {
+		return c
+	}
+	return codes.Unknown
+}
+
 func (e *DefaultError) GRPCStatus() *status.Status {
-	s := status.New(e.GRPCCodeField, e.Error())
+	s := status.New(e.GRPCCode(), e.Error())
 
 	st := e.StackTrace()
 	var stackEntries []string
@@ -221,11 +351,7 @@ func (e *DefaultError) GRPCStatus() *status.Status {
 		})
 	}
 
-	if e.ReasonField != "" {
-		details = append(details, &errdetails.ErrorInfo{
-			Reason: e.Reason(),
-		})
-	}
+	details = append(details, e.errorInfoDetails()...)
 
 	if e.RequestID() != "" {
 		details = append(details, &errdetails.RequestInfo{
@@ -233,12 +359,21 @@ func (e *DefaultError) GRPCStatus() *status.Status {
 		})
 	}
 
-	if e.GRPCCodeField == codes.InvalidArgument && e.err != nil {
-		if fvs := e.fieldViolations(); len(fvs) > 0 {
-			details = append(details, &errdetails.BadRequest{
-				FieldViolations: fvs,
-			})
+	details = append(details, e.retryDetails()...)
+	details = append(details, e.detailsProto()...)
+
+	if fvs := e.FieldViolations(); len(fvs) > 0 {
+		br := &errdetails.BadRequest{
+			FieldViolations: make([]*errdetails.BadRequest_FieldViolation, len(fvs)),
+		}
+		for i, fv := range fvs {
+			br.FieldViolations[i] = &errdetails.BadRequest_FieldViolation{
+				Field:       fv.Field,
+				Description: fv.Description,
+				Reason:      fv.Reason,
+			}
 		}
+		details = append(details, br)
 	}
 
 	s, err := s.WithDetails(details...)
@@ -281,26 +416,71 @@ func rootCauses(err fieldViolationError) []fieldViolationError {
 	return []fieldViolationError{err}
 }
 
-func (e *DefaultError) fieldViolations() (fv []*errdetails.BadRequest_FieldViolation) {
-	err, ok := e.err.(multiError)
-	if !ok {
-		return
-	}
-	for _, e := range err.AllErrors() {
-		if fvErr, ok := e.(fieldViolationError); ok {
-			// We only want to show the root cause of the error.
-			for _, cause := range rootCauses(fvErr) {
-				fv = append(fv, &errdetails.BadRequest_FieldViolation{
-					Field:       cause.Field(),
-					Description: cause.Reason(),
-				})
+// extractFieldViolations returns the field violations found by the registered adapters, followed by
+// the root causes of all fieldViolationError in the error's chain.
+func extractFieldViolations(err error) (fvs []FieldViolation) {
+	fvs = adaptFieldViolations(err)
+
+	var causes []fieldViolationError
+	if me := multiError(nil); stderr.As(err, &me) {
+		for _, e := range me.AllErrors() {
+			if fvErr, ok := e.(fieldViolationError); ok {
+				causes = append(causes, rootCauses(fvErr)...)
 			}
 		}
+	} else if fvErr := fieldViolationError(nil); stderr.As(err, &fvErr) {
+		causes = rootCauses(fvErr)
+	}
+
+	// We only want to show the root cause of the error.
+	for _, cause := range causes {
+		fvs = append(fvs, FieldViolation{
+			Field:       cause.Field(),
+			Description: cause.Reason(),
+		})
 	}
+	return
+}
 
+// fieldViolationsOf returns the field violations of the first carrier in the error's chain which has any,
+// so that they are not hidden by wrapping errors which implement FieldViolationsCarrier but do not have
+// violations. If no carrier has violations, they are extracted from the error.
+func fieldViolationsOf(err error) (fvs []FieldViolation) {
+	walkErrors(err, func(err error) {
+		if c, ok := err.(FieldViolationsCarrier); ok && len(fvs) == 0 {
+			fvs = slices.Clone(c.FieldViolations())
+		}
+	})
+	if len(fvs) == 0 {
+		fvs = extractFieldViolations(err)
+	}
 	return
 }
 
+// FieldViolations returns the field violations set using WithFieldViolation, followed by
+// those of the wrapped error.
+func (e *DefaultError) FieldViolations() []FieldViolation {
+	fvs := slices.Clone(e.FieldViolationsField)
+	if e.err == nil || e.err == e {
+		return fvs
+	}
+	for _, fv := range fieldViolationsOf(e.err) {
+		if !slices.Contains(fvs, fv) {
+			fvs = append(fvs, fv)
+		}
+	}
+	return fvs
+}
+
+// WithFieldViolation adds a field violation. Mutates and returns the receiver.
+func (e *DefaultError) WithFieldViolation(field, description string) *DefaultError {
+	e.FieldViolationsField = append(slices.Clone(e.FieldViolationsField), FieldViolation{
+		Field:       field,
+		Description: description,
+	})
+	return e
+}
+
 // WithReason sets the human-readable reason. Mutates and returns the receiver.
 func (e *DefaultError) WithReason(reason string) *DefaultError {
 	e.ReasonField = reason
@@ -312,9 +492,12 @@ func (e *DefaultError) WithReasonf(reason string, args ...interface{}) *DefaultE
 	return e.WithReason(fmt.Sprintf(reason, args...))
 }
 
-// WithError sets the error message. Mutates and returns the receiver.
+// WithError sets the error message. Because the message ID refers to the previous message,
+// it is cleared. Mutates and returns the receiver.
 func (e *DefaultError) WithError(message string) *DefaultError {
 	e.ErrorField = message
+	e.messageID = ""
+	e.messageArgs = nil
 	return e
 }
 
@@ -386,6 +569,36 @@ func ToDefaultError(err error, requestID string) *DefaultError {
 	}
 	de.Wrap(err)
 
+	// Errors returned by gRPC clients only carry a status, which we convert
+	// unless the error tells us its status code itself.
+	if c := StatusCodeCarrier(nil); !stderr.As(err, &c) {
+		if c := grpcStatusError(nil); stderr.As(err, &c) {
+			if se := FromGRPCStatus(c.GRPCStatus()); se != nil {
+				de.CodeField = se.CodeField
+				de.GRPCCodeField = se.GRPCCodeField
+				de.ErrorField = se.ErrorField
+				de.IDField = se.IDField
+				de.ReasonField = se.ReasonField
+				de.DomainField = se.DomainField
+				if se.DetailsField != nil {
+					de.DetailsField = se.DetailsField
+				}
+				de.DebugField = se.DebugField
+				de.retryAfter = se.retryAfter
+				de.rateLimit = se.rateLimit
+				de.FieldViolationsField = se.FieldViolationsField
+				de.PreconditionViolationsField = se.PreconditionViolationsField
+				de.QuotaViolationsField = se.QuotaViolationsField
+				de.ResourceInfoField = se.ResourceInfoField
+				de.HelpLinksField = se.HelpLinksField
+				de.LocalizedMessageField = se.LocalizedMessageField
+				if se.RIDField != "" {
+					de.RIDField = se.RIDField
+				}
+			}
+		}
+	}
+
 	if c := ReasonCarrier(nil); stderr.As(err, &c) {
 		de.ReasonField = c.Reason()
 	}
@@ -395,32 +608,75 @@ func ToDefaultError(err error, requestID string) *DefaultError {
 	if c := DetailsCarrier(nil); stderr.As(err, &c) && c.Details() != nil {
 		de.DetailsField = c.Details()
 	}
+	if fvs := fieldViolationsOf(err); len(fvs) > 0 {
+		de.FieldViolationsField = fvs
+	}
 	if c := StatusCarrier(nil); stderr.As(err, &c) && c.Status() != "" {
 		de.StatusField = c.Status()
 	}
 	if c := StatusCodeCarrier(nil); stderr.As(err, &c) && c.StatusCode() != 0 {
 		de.CodeField = c.StatusCode()
 	}
+	if c := GRPCCodeCarrier(nil); stderr.As(err, &c) {
+		de.GRPCCodeField = c.GRPCCode()
+	}
 	if c := DebugCarrier(nil); stderr.As(err, &c) {
 		de.DebugField = c.Debug()
 	}
 	if c := IDCarrier(nil); stderr.As(err, &c) {
 		de.IDField = c.ID()
 	}
+	if id, args := messageOf(err); id != "" {
+		de.messageID = id
+		de.messageArgs = maps.Clone(args)
+	}
+	if domain := domainOf(err); domain != "" {
+		de.DomainField = domain
+	}
+	de.headers = collectHeaders(err)
+	de.collectDetails(err)
+	de.WarningsField = warningsOf(err)
+	if d := retryAfterOf(err); d > 0 {
+		de.retryAfter = d
+	}
+	if rl := rateLimitOf(err); rl != nil {
+		de.rateLimit = rl.clone()
+	}
 
 	if de.StatusField == "" {
 		de.StatusField = http.StatusText(de.StatusCode())
 	}
+	if de.GRPCCodeField == codes.OK {
+		de.GRPCCodeField = de.GRPCCode()
+	}
 
 	return de
 }
 
+// statusCodeOf returns the HTTP status code of err, which is taken from a StatusCodeCarrier or derived
+// from the gRPC status of the error. If neither is available, the status code is 500.
+func statusCodeOf(err error) int {
+	if c := StatusCodeCarrier(nil); stderr.As(err, &c) {
+		return c.StatusCode()
+	}
+	if c := grpcStatusError(nil); stderr.As(err, &c) && c.GRPCStatus().Code() != codes.OK {
+		return HTTPStatusFromGRPCCode(c.GRPCStatus().Code())
+	}
+	return http.StatusInternalServerError
+}
+
 // StatusCodeCarrier can be implemented by an error to support setting status codes in the error itself.
 type StatusCodeCarrier interface {
 	// StatusCode returns the status code of this error.
 	StatusCode() int
 }
 
+// GRPCCodeCarrier can be implemented by an error to support setting gRPC codes in the error itself.
+type GRPCCodeCarrier interface {
+	// GRPCCode returns the gRPC code of this error.
+	GRPCCode() codes.Code
+}
+
 // RequestIDCarrier can be implemented by an error to support error contexts.
 type RequestIDCarrier interface {
 	// RequestID returns the ID of the request that caused the error, if applicable.
@@ -451,6 +707,28 @@ type DetailsCarrier interface {
 	Details() map[string]interface{}
 }
 
+// DomainCarrier can be implemented by an error to support error contexts.
+type DomainCarrier interface {
+	// Domain returns the logical grouping of the error ID, if applicable.
+	Domain() string
+}
+
+// domainOf returns the domain of the first carrier in the error's chain which has one.
+func domainOf(err error) (domain string) {
+	walkErrors(err, func(err error) {
+		if c, ok := err.(DomainCarrier); ok && domain == "" {
+			domain = c.Domain()
+		}
+	})
+	return
+}
+
+// FieldViolationsCarrier can be implemented by an error to support error contexts.
+type FieldViolationsCarrier interface {
+	// FieldViolations returns the fields of the request which failed validation, if applicable.
+	FieldViolations() []FieldViolation
+}
+
 // IDCarrier can be implemented by an error to support error contexts.
 type IDCarrier interface {
 	// ID returns application error ID on the error, if applicable.
@@ -4,6 +4,7 @@
 package herodot
 
 import (
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -122,8 +123,31 @@ func TestToDefaultError(t *testing.T) {
 
 		assert.Equal(t, expected, status)
 	})
+
+	t.Run("case=empty carriers", func(t *testing.T) {
+		inner := ErrNotFound().
+			WithDomain("kratos.ory.sh").
+			WithMessageID("user_not_found").
+			WithMessageArg("user", "1234").
+			WithWarning("warning")
+		de := ToDefaultError(&emptyCarrierError{error: inner}, "")
+
+		assert.Equal(t, "kratos.ory.sh", de.Domain())
+		assert.Equal(t, "user_not_found", de.MessageID())
+		assert.Equal(t, map[string]interface{}{"user": "1234"}, de.MessageArgs())
+		assert.Equal(t, []string{"warning"}, de.Warnings())
+	})
 }
 
+// emptyCarrierError implements the carriers without carrying anything.
+type emptyCarrierError struct{ error }
+
+func (e *emptyCarrierError) Unwrap() error                       { return e.error }
+func (e *emptyCarrierError) Domain() string                      { return "" }
+func (e *emptyCarrierError) MessageID() string                   { return "" }
+func (e *emptyCarrierError) MessageArgs() map[string]interface{} { return nil }
+func (e *emptyCarrierError) Warnings() []string                  { return nil }
+
 func TestOmitDebug(t *testing.T) {
 	t.Run("case=without debug (default)", func(t *testing.T) {
 		e := &DefaultError{
@@ -154,3 +178,95 @@ func TestOmitDebug(t *testing.T) {
 		assert.JSONEq(t, `{"message":"Some Error", "debug": "whatever"}`, string(j))
 	})
 }
+
+// validationError mimics the errors generated by protoc-gen-validate.
+type validationError struct {
+	field, reason string
+	cause         error
+}
+
+func (e validationError) Field() string  { return e.field }
+func (e validationError) Reason() string { return e.reason }
+func (e validationError) Cause() error   { return e.cause }
+func (e validationError) Error() string  { return e.field + ": " + e.reason }
+
+type validationMultiError []error
+
+func (m validationMultiError) AllErrors() []error { return m }
+func (m validationMultiError) Error() string      { return "multiple validation errors" }
+
+type fieldViolationsCarrier []FieldViolation
+
+func (c fieldViolationsCarrier) Error() string                     { return "invalid" }
+func (c fieldViolationsCarrier) FieldViolations() []FieldViolation { return c }
+
+func TestFieldViolations(t *testing.T) {
+	expected := []FieldViolation{
+		{Field: "email", Description: "must be a valid email address"},
+		{Field: "name", Description: "must not be empty"},
+	}
+
+	for _, tc := range []struct {
+		name string
+		err  *DefaultError
+	}{
+		{
+			name: "explicit",
+			err: ErrBadRequest().
+				WithFieldViolation("email", "must be a valid email address").
+				WithFieldViolation("name", "must not be empty"),
+		},
+		{
+			name: "multi error",
+			err: ErrBadRequest().WithWrap(validationMultiError{
+				validationError{field: "email", reason: "must be a valid email address"},
+				validationError{field: "user", reason: "invalid", cause: validationError{field: "name", reason: "must not be empty"}},
+			}),
+		},
+		{
+			name: "wrapped carrier",
+			err: ErrBadRequest().WithWrap(errors.WithStack(fieldViolationsCarrier{
+				{Field: "email", Description: "must be a valid email address"},
+				{Field: "name", Description: "must not be empty"},
+			})),
+		},
+		{
+			name: "single error",
+			err: ErrBadRequest().
+				WithFieldViolation("email", "must be a valid email address").
+				WithWrap(errors.WithStack(validationError{field: "name", reason: "must not be empty"})),
+		},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			assert.Equal(t, expected, tc.err.FieldViolations())
+			assert.Equal(t, expected, tc.err.Clone().FieldViolations())
+			assert.Equal(t, expected, ToDefaultError(errors.WithStack(tc.err), "").FieldViolationsField)
+
+			rec := httptest.NewRecorder()
+			NewJSONWriter(nil).WriteError(rec, httptest.NewRequest("GET", "/", nil), tc.err)
+			var ec ErrorContainer
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&ec))
+			assert.Equal(t, expected, ec.Error.FieldViolationsField)
+
+			var br *errdetails.BadRequest
+			for _, d := range tc.err.GRPCStatus().Details() {
+				if d, ok := d.(*errdetails.BadRequest); ok {
+					br = d
+				}
+			}
+			require.NotNil(t, br)
+			require.Len(t, br.FieldViolations, 2)
+			assert.Equal(t, "name", br.FieldViolations[1].Field)
+			assert.Equal(t, "must not be empty", br.FieldViolations[1].Description)
+
+			assert.Equal(t, expected, FromGRPCStatus(tc.err.GRPCStatus()).FieldViolations())
+		})
+	}
+
+	t.Run("case=clone does not share violations", func(t *testing.T) {
+		e := ErrBadRequest().WithFieldViolation("a", "b")
+		c := e.Clone().WithFieldViolation("c", "d")
+		assert.Len(t, e.FieldViolations(), 1)
+		assert.Len(t, c.FieldViolations(), 2)
+	})
+}
@@ -15,6 +15,7 @@ func ErrNotFound() *DefaultError {
 		ErrorField:    "The requested resource could not be found",
 		CodeField:     http.StatusNotFound,
 		GRPCCodeField: codes.NotFound,
+		messageID:     "herodot.not_found",
 	}
 }
 
@@ -24,6 +25,7 @@ func ErrUnauthorized() *DefaultError {
 		ErrorField:    "The request could not be authorized",
 		CodeField:     http.StatusUnauthorized,
 		GRPCCodeField: codes.Unauthenticated,
+		messageID:     "herodot.unauthorized",
 	}
 }
 
@@ -33,6 +35,7 @@ func ErrForbidden() *DefaultError {
 		ErrorField:    "The requested action was forbidden",
 		CodeField:     http.StatusForbidden,
 		GRPCCodeField: codes.PermissionDenied,
+		messageID:     "herodot.forbidden",
 	}
 }
 
@@ -42,6 +45,7 @@ func ErrInternalServerError() *DefaultError {
 		ErrorField:    "An internal server error occurred, please contact the system administrator",
 		CodeField:     http.StatusInternalServerError,
 		GRPCCodeField: codes.Internal,
+		messageID:     "herodot.internal_server_error",
 	}
 }
 
@@ -51,6 +55,7 @@ func ErrBadRequest() *DefaultError {
 		ErrorField:    "The request was malformed or contained invalid parameters",
 		CodeField:     http.StatusBadRequest,
 		GRPCCodeField: codes.InvalidArgument,
+		messageID:     "herodot.bad_request",
 	}
 }
 
@@ -60,6 +65,7 @@ func ErrUnsupportedMediaType() *DefaultError {
 		ErrorField:    "The request is using an unknown content type",
 		CodeField:     http.StatusUnsupportedMediaType,
 		GRPCCodeField: codes.InvalidArgument,
+		messageID:     "herodot.unsupported_media_type",
 	}
 }
 
@@ -69,6 +75,7 @@ func ErrConflict() *DefaultError {
 		ErrorField:    "The resource could not be created due to a conflict",
 		CodeField:     http.StatusConflict,
 		GRPCCodeField: codes.FailedPrecondition,
+		messageID:     "herodot.conflict",
 	}
 }
 
@@ -80,15 +87,159 @@ func ErrMisconfiguration() *DefaultError {
 		ReasonField:   "One or more configuration values are invalid. Please report this to the system administrator.",
 		CodeField:     http.StatusInternalServerError,
 		GRPCCodeField: codes.Internal,
+		messageID:     "herodot.misconfiguration",
 	}
 }
 
 func ErrUpstreamError() *DefaultError {
 	return &DefaultError{
-		IDField:     "upstream_error",
-		StatusField: http.StatusText(http.StatusBadGateway),
-		ErrorField:  "Upstream error",
-		ReasonField: "An upstream server encountered an error or returned a malformed or unexpected response.",
-		CodeField:   http.StatusBadGateway,
+		IDField:       "upstream_error",
+		StatusField:   http.StatusText(http.StatusBadGateway),
+		ErrorField:    "Upstream error",
+		ReasonField:   "An upstream server encountered an error or returned a malformed or unexpected response.",
+		CodeField:     http.StatusBadGateway,
+		GRPCCodeField: codes.Unavailable,
+		messageID:     "herodot.upstream_error",
+	}
+}
+
+func ErrNotAcceptable() *DefaultError {
+	return &DefaultError{
+		StatusField:   http.StatusText(http.StatusNotAcceptable),
+		ErrorField:    "The requested content type is not available",
+		CodeField:     http.StatusNotAcceptable,
+		GRPCCodeField: codes.InvalidArgument,
+		messageID:     "herodot.not_acceptable",
+	}
+}
+
+func ErrUnsupportedVersion() *DefaultError {
+	return &DefaultError{
+		IDField:       "unsupported_version",
+		StatusField:   http.StatusText(http.StatusBadRequest),
+		ErrorField:    "The requested API version is not supported",
+		CodeField:     http.StatusBadRequest,
+		GRPCCodeField: codes.InvalidArgument,
+		messageID:     "herodot.unsupported_version",
+	}
+}
+
+func ErrMethodNotAllowed() *DefaultError {
+	return &DefaultError{
+		StatusField:   http.StatusText(http.StatusMethodNotAllowed),
+		ErrorField:    "The request method is not supported by the requested resource",
+		CodeField:     http.StatusMethodNotAllowed,
+		GRPCCodeField: codes.Unimplemented,
+		messageID:     "herodot.method_not_allowed",
+	}
+}
+
+func ErrRequestTimeout() *DefaultError {
+	return &DefaultError{
+		StatusField:   http.StatusText(http.StatusRequestTimeout),
+		ErrorField:    "The request could not be completed in time",
+		CodeField:     http.StatusRequestTimeout,
+		GRPCCodeField: codes.DeadlineExceeded,
+		messageID:     "herodot.request_timeout",
+	}
+}
+
+func ErrGone() *DefaultError {
+	return &DefaultError{
+		StatusField:   http.StatusText(http.StatusGone),
+		ErrorField:    "The requested resource is no longer available",
+		CodeField:     http.StatusGone,
+		GRPCCodeField: codes.NotFound,
+		messageID:     "herodot.gone",
+	}
+}
+
+func ErrPreconditionFailed() *DefaultError {
+	return &DefaultError{
+		StatusField:   http.StatusText(http.StatusPreconditionFailed),
+		ErrorField:    "One or more preconditions of the request are not met",
+		CodeField:     http.StatusPreconditionFailed,
+		GRPCCodeField: codes.FailedPrecondition,
+		messageID:     "herodot.precondition_failed",
+	}
+}
+
+func ErrRequestEntityTooLarge() *DefaultError {
+	return &DefaultError{
+		StatusField:   http.StatusText(http.StatusRequestEntityTooLarge),
+		ErrorField:    "The request body is too large",
+		CodeField:     http.StatusRequestEntityTooLarge,
+		GRPCCodeField: codes.InvalidArgument,
+		messageID:     "herodot.request_entity_too_large",
+	}
+}
+
+func ErrRequestedRangeNotSatisfiable() *DefaultError {
+	return &DefaultError{
+		StatusField:   http.StatusText(http.StatusRequestedRangeNotSatisfiable),
+		ErrorField:    "The requested range is out of bounds",
+		CodeField:     http.StatusRequestedRangeNotSatisfiable,
+		GRPCCodeField: codes.OutOfRange,
+		messageID:     "herodot.requested_range_not_satisfiable",
+	}
+}
+
+func ErrUnprocessableEntity() *DefaultError {
+	return &DefaultError{
+		StatusField:   http.StatusText(http.StatusUnprocessableEntity),
+		ErrorField:    "The request was well-formed but contained semantic errors",
+		CodeField:     http.StatusUnprocessableEntity,
+		GRPCCodeField: codes.InvalidArgument,
+		messageID:     "herodot.unprocessable_entity",
+	}
+}
+
+func ErrTooManyRequests() *DefaultError {
+	return &DefaultError{
+		StatusField:   http.StatusText(http.StatusTooManyRequests),
+		ErrorField:    "Too many requests were sent, please try again later",
+		CodeField:     http.StatusTooManyRequests,
+		GRPCCodeField: codes.ResourceExhausted,
+		messageID:     "herodot.too_many_requests",
+	}
+}
+
+func ErrClientClosedRequest() *DefaultError {
+	return &DefaultError{
+		StatusField:   "Client Closed Request",
+		ErrorField:    "The client canceled the request",
+		CodeField:     StatusClientClosedRequest,
+		GRPCCodeField: codes.Canceled,
+		messageID:     "herodot.client_closed_request",
+	}
+}
+
+func ErrNotImplemented() *DefaultError {
+	return &DefaultError{
+		StatusField:   http.StatusText(http.StatusNotImplemented),
+		ErrorField:    "The requested functionality is not implemented",
+		CodeField:     http.StatusNotImplemented,
+		GRPCCodeField: codes.Unimplemented,
+		messageID:     "herodot.not_implemented",
+	}
+}
+
+func ErrServiceUnavailable() *DefaultError {
+	return &DefaultError{
+		StatusField:   http.StatusText(http.StatusServiceUnavailable),
+		ErrorField:    "The service is currently unavailable, please try again later",
+		CodeField:     http.StatusServiceUnavailable,
+		GRPCCodeField: codes.Unavailable,
+		messageID:     "herodot.service_unavailable",
+	}
+}
+
+func ErrGatewayTimeout() *DefaultError {
+	return &DefaultError{
+		StatusField:   http.StatusText(http.StatusGatewayTimeout),
+		ErrorField:    "An upstream server did not respond in time",
+		CodeField:     http.StatusGatewayTimeout,
+		GRPCCodeField: codes.DeadlineExceeded,
+		messageID:     "herodot.gateway_timeout",
 	}
 }
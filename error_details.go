@@ -0,0 +1,233 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"slices"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// PreconditionViolation describes a precondition which failed, e.g. terms of service which were not
+// accepted. It corresponds to google.rpc.PreconditionFailure.Violation.
+type PreconditionViolation struct {
+	// The type of the precondition, e.g. `TOS`.
+	Type string `json:"type"`
+
+	// The subject, relative to the type, which failed, e.g. `google.com/cloud`.
+	Subject string `json:"subject"`
+
+	// A human-readable description of how the precondition failed.
+	Description string `json:"description"`
+}
+
+// ResourceInfo describes the resource which is being accessed. It corresponds to google.rpc.ResourceInfo.
+type ResourceInfo struct {
+	// The type of the resource, e.g. `identity`.
+	ResourceType string `json:"resource_type"`
+
+	// The name of the resource, e.g. its ID.
+	ResourceName string `json:"resource_name"`
+
+	// The owner of the resource, if any.
+	Owner string `json:"owner,omitempty"`
+
+	// A human-readable description of the error encountered when accessing the resource.
+	Description string `json:"description,omitempty"`
+}
+
+// HelpLink links to documentation about the error. It corresponds to google.rpc.Help.Link.
+type HelpLink struct {
+	// A human-readable description of what the link offers.
+	Description string `json:"description"`
+
+	// The URL of the link.
+	URL string `json:"url"`
+}
+
+// LocalizedMessage is an error message which is safe to show to the end user. It corresponds
+// to google.rpc.LocalizedMessage.
+type LocalizedMessage struct {
+	// The BCP 47 language tag of the message, e.g. `en-US`.
+	Locale string `json:"locale"`
+
+	// The localized message.
+	Message string `json:"message"`
+}
+
+// QuotaViolation describes a quota which was exceeded. It corresponds to google.rpc.QuotaFailure.Violation.
+// Violations of the rate limit set using DefaultError.WithRateLimit are added automatically.
+type QuotaViolation struct {
+	// The subject on which the quota check failed, e.g. `project:1234`.
+	Subject string `json:"subject"`
+
+	// A human-readable description of how the quota was exceeded.
+	Description string `json:"description"`
+}
+
+// PreconditionViolationsCarrier can be implemented by an error to support error contexts.
+type PreconditionViolationsCarrier interface {
+	// PreconditionViolations returns the failed preconditions, if applicable.
+	PreconditionViolations() []PreconditionViolation
+}
+
+// ResourceInfoCarrier can be implemented by an error to support error contexts.
+type ResourceInfoCarrier interface {
+	// ResourceInfo returns the resource which is being accessed, if applicable.
+	ResourceInfo() *ResourceInfo
+}
+
+// HelpLinksCarrier can be implemented by an error to support error contexts.
+type HelpLinksCarrier interface {
+	// HelpLinks returns links to documentation about the error, if applicable.
+	HelpLinks() []HelpLink
+}
+
+// LocalizedMessageCarrier can be implemented by an error to support error contexts.
+type LocalizedMessageCarrier interface {
+	// LocalizedMessage returns a message which is safe to show to the end user, if applicable.
+	LocalizedMessage() *LocalizedMessage
+}
+
+// QuotaViolationsCarrier can be implemented by an error to support error contexts.
+type QuotaViolationsCarrier interface {
+	// QuotaViolations returns the exceeded quotas, if applicable.
+	QuotaViolations() []QuotaViolation
+}
+
+func (e *DefaultError) PreconditionViolations() []PreconditionViolation {
+	return e.PreconditionViolationsField
+}
+
+// WithPreconditionViolation adds a failed precondition. Mutates and returns the receiver.
+func (e *DefaultError) WithPreconditionViolation(typ, subject, description string) *DefaultError {
+	e.PreconditionViolationsField = append(slices.Clone(e.PreconditionViolationsField), PreconditionViolation{
+		Type:        typ,
+		Subject:     subject,
+		Description: description,
+	})
+	return e
+}
+
+func (e *DefaultError) ResourceInfo() *ResourceInfo {
+	return e.ResourceInfoField
+}
+
+// WithResourceInfo sets the resource which is being accessed. Mutates and returns the receiver.
+func (e *DefaultError) WithResourceInfo(ri ResourceInfo) *DefaultError {
+	e.ResourceInfoField = &ri
+	return e
+}
+
+func (e *DefaultError) HelpLinks() []HelpLink {
+	return e.HelpLinksField
+}
+
+// WithHelpLink adds a link to documentation about the error. Mutates and returns the receiver.
+func (e *DefaultError) WithHelpLink(description, url string) *DefaultError {
+	e.HelpLinksField = append(slices.Clone(e.HelpLinksField), HelpLink{
+		Description: description,
+		URL:         url,
+	})
+	return e
+}
+
+func (e *DefaultError) LocalizedMessage() *LocalizedMessage {
+	return e.LocalizedMessageField
+}
+
+// WithLocalizedMessage sets a message which is safe to show to the end user. Mutates and returns the receiver.
+func (e *DefaultError) WithLocalizedMessage(locale, message string) *DefaultError {
+	e.LocalizedMessageField = &LocalizedMessage{
+		Locale:  locale,
+		Message: message,
+	}
+	return e
+}
+
+func (e *DefaultError) QuotaViolations() []QuotaViolation {
+	return e.QuotaViolationsField
+}
+
+// WithQuotaViolation adds an exceeded quota. Mutates and returns the receiver.
+func (e *DefaultError) WithQuotaViolation(subject, description string) *DefaultError {
+	e.QuotaViolationsField = append(slices.Clone(e.QuotaViolationsField), QuotaViolation{
+		Subject:     subject,
+		Description: description,
+	})
+	return e
+}
+
+// collectDetails sets the details of the carriers in the error's chain. For each kind of detail,
+// the first carrier which has it wins, so that details set on deeply wrapped errors are not hidden
+// by wrapping errors which implement the carrier but do not have the detail.
+func (e *DefaultError) collectDetails(err error) {
+	walkErrors(err, func(err error) {
+		if c, ok := err.(PreconditionViolationsCarrier); ok && len(e.PreconditionViolationsField) == 0 {
+			e.PreconditionViolationsField = slices.Clone(c.PreconditionViolations())
+		}
+		if c, ok := err.(ResourceInfoCarrier); ok && e.ResourceInfoField == nil {
+			e.ResourceInfoField = clonePtr(c.ResourceInfo())
+		}
+		if c, ok := err.(HelpLinksCarrier); ok && len(e.HelpLinksField) == 0 {
+			e.HelpLinksField = slices.Clone(c.HelpLinks())
+		}
+		if c, ok := err.(LocalizedMessageCarrier); ok && e.LocalizedMessageField == nil {
+			e.LocalizedMessageField = clonePtr(c.LocalizedMessage())
+		}
+		if c, ok := err.(QuotaViolationsCarrier); ok && len(e.QuotaViolationsField) == 0 {
+			e.QuotaViolationsField = slices.Clone(c.QuotaViolations())
+		}
+	})
+}
+
+// detailsProto returns the gRPC status details for the failed preconditions, the resource info,
+// the help links and the localized message.
+func (e *DefaultError) detailsProto() (details []protoadapt.MessageV1) {
+	if ri := e.ResourceInfoField; ri != nil {
+		details = append(details, &errdetails.ResourceInfo{
+			ResourceType: ri.ResourceType,
+			ResourceName: ri.ResourceName,
+			Owner:        ri.Owner,
+			Description:  ri.Description,
+		})
+	}
+	if len(e.PreconditionViolationsField) > 0 {
+		pf := &errdetails.PreconditionFailure{}
+		for _, v := range e.PreconditionViolationsField {
+			pf.Violations = append(pf.Violations, &errdetails.PreconditionFailure_Violation{
+				Type:        v.Type,
+				Subject:     v.Subject,
+				Description: v.Description,
+			})
+		}
+		details = append(details, pf)
+	}
+	if len(e.HelpLinksField) > 0 {
+		help := &errdetails.Help{}
+		for _, l := range e.HelpLinksField {
+			help.Links = append(help.Links, &errdetails.Help_Link{
+				Description: l.Description,
+				Url:         l.URL,
+			})
+		}
+		details = append(details, help)
+	}
+	if lm := e.LocalizedMessageField; lm != nil {
+		details = append(details, &errdetails.LocalizedMessage{
+			Locale:  lm.Locale,
+			Message: lm.Message,
+		})
+	}
+	return
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	return &c
+}
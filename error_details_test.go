@@ -0,0 +1,97 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+type storageError struct {
+	error
+	resource *ResourceInfo
+}
+
+func (e *storageError) ResourceInfo() *ResourceInfo { return e.resource }
+func (e *storageError) Unwrap() error               { return e.error }
+
+func TestErrorDetails(t *testing.T) {
+	newError := func() *DefaultError {
+		return ErrPreconditionFailed().
+			WithPreconditionViolation("TOS", "ory.sh/terms", "Terms of service not accepted").
+			WithQuotaViolation("project:1234", "Daily limit exceeded").
+			WithHelpLink("Accepting the terms of service", "https://www.ory.sh/docs/tos").
+			WithLocalizedMessage("de-DE", "Nutzungsbedingungen nicht akzeptiert").
+			WithWrap(&storageError{
+				error:    errors.New("no rows"),
+				resource: &ResourceInfo{ResourceType: "identity", ResourceName: "1234", Owner: "project:1234"},
+			})
+	}
+
+	check := func(t *testing.T, e *DefaultError) {
+		assert.Equal(t, []PreconditionViolation{{Type: "TOS", Subject: "ory.sh/terms", Description: "Terms of service not accepted"}}, e.PreconditionViolations())
+		assert.Equal(t, []QuotaViolation{{Subject: "project:1234", Description: "Daily limit exceeded"}}, e.QuotaViolations())
+		assert.Equal(t, []HelpLink{{Description: "Accepting the terms of service", URL: "https://www.ory.sh/docs/tos"}}, e.HelpLinks())
+		assert.Equal(t, &LocalizedMessage{Locale: "de-DE", Message: "Nutzungsbedingungen nicht akzeptiert"}, e.LocalizedMessage())
+		assert.Equal(t, &ResourceInfo{ResourceType: "identity", ResourceName: "1234", Owner: "project:1234"}, e.ResourceInfo())
+	}
+
+	t.Run("case=to default error", func(t *testing.T) {
+		check(t, ToDefaultError(errors.WithStack(newError()), ""))
+	})
+
+	t.Run("case=clone", func(t *testing.T) {
+		e := ToDefaultError(newError(), "")
+		c := e.Clone()
+		c.ResourceInfoField.Owner = "changed"
+		c.WithHelpLink("other", "https://example.com")
+		check(t, e)
+	})
+
+	t.Run("case=json", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewJSONWriter(nil).WriteError(rec, httptest.NewRequest("GET", "/", nil), newError())
+
+		var ec ErrorContainer
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&ec))
+		check(t, ec.Error)
+	})
+
+	t.Run("case=grpc", func(t *testing.T) {
+		s := ToDefaultError(newError(), "").GRPCStatus()
+
+		var found []string
+		for _, d := range s.Details() {
+			switch d := d.(type) {
+			case *errdetails.ResourceInfo:
+				found = append(found, "resource")
+				assert.Equal(t, "identity", d.ResourceType)
+			case *errdetails.PreconditionFailure:
+				found = append(found, "precondition")
+				require.Len(t, d.Violations, 1)
+				assert.Equal(t, "TOS", d.Violations[0].Type)
+			case *errdetails.Help:
+				found = append(found, "help")
+				require.Len(t, d.Links, 1)
+				assert.Equal(t, "https://www.ory.sh/docs/tos", d.Links[0].Url)
+			case *errdetails.LocalizedMessage:
+				found = append(found, "localized")
+				assert.Equal(t, "de-DE", d.Locale)
+			case *errdetails.QuotaFailure:
+				found = append(found, "quota")
+				require.Len(t, d.Violations, 1)
+				assert.Equal(t, "project:1234", d.Violations[0].Subject)
+			}
+		}
+		assert.ElementsMatch(t, []string{"resource", "precondition", "help", "localized", "quota"}, found)
+
+		check(t, FromGRPCStatus(s))
+	})
+}
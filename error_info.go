@@ -0,0 +1,175 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// The metadata keys of errdetails.ErrorInfo which are reserved for the error ID and reason.
+// Details using these keys are carried in the google.protobuf.Struct detail instead.
+const (
+	ErrorInfoMetadataID     = "id"
+	ErrorInfoMetadataReason = "reason"
+)
+
+// The domain, reason and metadata key of the errdetails.ErrorInfo which carries the HTTP status code
+// if it can not be derived from the gRPC code. The status code is only restored from an ErrorInfo
+// of this domain, ErrorInfo details of other domains can not change it.
+const (
+	ErrorInfoDomain             = "herodot.ory.sh"
+	ErrorInfoReasonStatusCode   = "HTTP_STATUS_CODE"
+	ErrorInfoMetadataStatusCode = "status_code"
+)
+
+// errorInfoDetails returns the gRPC status details representing the error's ID, reason, domain and details:
+//
+//   - ErrorInfo.Reason is the error ID or, if the error has no ID, the human-readable reason.
+//   - ErrorInfo.Domain is the error domain.
+//   - ErrorInfo.Metadata contains the error ID and the human-readable reason under the keys
+//     ErrorInfoMetadataID and ErrorInfoMetadataReason if the error has an ID, as well as all
+//     details whose value is a string, boolean or number.
+//   - All other details are carried in a google.protobuf.Struct.
+//   - If the HTTP status code can not be derived from the gRPC code, it is carried in a second
+//     ErrorInfo of the domain ErrorInfoDomain.
+func (e *DefaultError) errorInfoDetails() (details []protoadapt.MessageV1) {
+	info := &errdetails.ErrorInfo{
+		Reason: e.ReasonField,
+		Domain: e.DomainField,
+	}
+	complexDetails := map[string]interface{}{}
+
+	for k, v := range e.DetailsField {
+		if s, ok := stringify(v); ok && k != ErrorInfoMetadataID && k != ErrorInfoMetadataReason {
+			if info.Metadata == nil {
+				info.Metadata = map[string]string{}
+			}
+			info.Metadata[k] = s
+			continue
+		}
+		complexDetails[k] = v
+	}
+
+	if e.IDField != "" {
+		if info.Metadata == nil {
+			info.Metadata = map[string]string{}
+		}
+		info.Reason = e.IDField
+		info.Metadata[ErrorInfoMetadataID] = e.IDField
+		if e.ReasonField != "" {
+			info.Metadata[ErrorInfoMetadataReason] = e.ReasonField
+		}
+	}
+
+	if info.Reason != "" || info.Domain != "" || len(info.Metadata) > 0 {
+		details = append(details, info)
+	}
+	if s := toStruct(complexDetails); s != nil {
+		details = append(details, s)
+	}
+
+	// Several HTTP status codes share a gRPC code, e.g. 409 and 412, so the status code is carried along.
+	if code := e.StatusCode(); code != 0 && code != HTTPStatusFromGRPCCode(e.GRPCCode()) {
+		details = append(details, &errdetails.ErrorInfo{
+			Reason:   ErrorInfoReasonStatusCode,
+			Domain:   ErrorInfoDomain,
+			Metadata: map[string]string{ErrorInfoMetadataStatusCode: strconv.Itoa(code)},
+		})
+	}
+	return
+}
+
+// isStatusCodeInfo reports whether the ErrorInfo carries the HTTP status code.
+func isStatusCodeInfo(info *errdetails.ErrorInfo) bool {
+	return info.GetDomain() == ErrorInfoDomain && info.GetReason() == ErrorInfoReasonStatusCode
+}
+
+// applyStatusCodeInfo restores the HTTP status code from an ErrorInfo of the domain ErrorInfoDomain.
+func (e *DefaultError) applyStatusCodeInfo(info *errdetails.ErrorInfo) {
+	if code, err := strconv.Atoi(info.GetMetadata()[ErrorInfoMetadataStatusCode]); err == nil && code > 0 {
+		e.CodeField = code
+	}
+}
+
+// applyErrorInfo restores the ID, reason, domain and details from an ErrorInfo created by errorInfoDetails.
+func (e *DefaultError) applyErrorInfo(info *errdetails.ErrorInfo) {
+	e.DomainField = info.GetDomain()
+	e.ReasonField = info.GetReason()
+
+	for k, v := range info.GetMetadata() {
+		switch k {
+		case ErrorInfoMetadataID:
+			e.IDField = v
+			e.ReasonField = info.GetMetadata()[ErrorInfoMetadataReason]
+		case ErrorInfoMetadataReason:
+		default:
+			e.WithDetail(k, v)
+		}
+	}
+}
+
+// applyDetailsStruct restores the details carried in a google.protobuf.Struct.
+func (e *DefaultError) applyDetailsStruct(s *structpb.Struct) {
+	if len(s.GetFields()) == 0 {
+		return
+	}
+	if e.DetailsField == nil {
+		e.DetailsField = map[string]interface{}{}
+	} else {
+		e.DetailsField = maps.Clone(e.DetailsField)
+	}
+	maps.Copy(e.DetailsField, s.AsMap())
+}
+
+func stringify(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case json.Number:
+		return v.String(), true
+	}
+	return "", false
+}
+
+// toStruct converts details to a google.protobuf.Struct using their JSON representation.
+// Details which can not be represented as JSON are formatted as strings.
+func toStruct(details map[string]interface{}) *structpb.Struct {
+	if len(details) == 0 {
+		return nil
+	}
+
+	s := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(details))}
+	for k, v := range details {
+		var generic interface{}
+		raw, err := json.Marshal(v)
+		if err == nil {
+			err = json.Unmarshal(raw, &generic)
+		}
+		if err != nil {
+			generic = fmt.Sprintf("%v", v)
+		}
+
+		value, err := structpb.NewValue(generic)
+		if err != nil {
+			value = structpb.NewStringValue(fmt.Sprintf("%v", v))
+		}
+		s.Fields[k] = value
+	}
+	return s
+}
@@ -0,0 +1,116 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestErrorInfo(t *testing.T) {
+	e := ErrNotFound().
+		WithID("user_not_found").
+		WithDomain("kratos.ory.sh").
+		WithReason("User 1234 does not exist").
+		WithDetail("user", "1234").
+		WithDetail("attempts", 3).
+		WithDetail("id", "reserved").
+		WithDetail("traits", map[string]interface{}{"email": "foo@ory.sh"})
+
+	s := e.GRPCStatus()
+
+	var info *errdetails.ErrorInfo
+	var details *structpb.Struct
+	for _, d := range s.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *structpb.Struct:
+			details = d
+		}
+	}
+	require.NotNil(t, info)
+	require.NotNil(t, details)
+
+	assert.Equal(t, "user_not_found", info.Reason)
+	assert.Equal(t, "kratos.ory.sh", info.Domain)
+	assert.Equal(t, map[string]string{
+		"id":       "user_not_found",
+		"reason":   "User 1234 does not exist",
+		"user":     "1234",
+		"attempts": "3",
+	}, info.Metadata)
+	assert.Equal(t, map[string]interface{}{
+		"id":     "reserved",
+		"traits": map[string]interface{}{"email": "foo@ory.sh"},
+	}, details.AsMap())
+
+	t.Run("case=round trip", func(t *testing.T) {
+		s, ok := status.FromError(status.ErrorProto(s.Proto()))
+		require.True(t, ok)
+
+		actual := FromGRPCStatus(s)
+		assert.Equal(t, "user_not_found", actual.ID())
+		assert.Equal(t, "kratos.ory.sh", actual.Domain())
+		assert.Equal(t, "User 1234 does not exist", actual.Reason())
+		assert.Equal(t, map[string]interface{}{
+			"user":     "1234",
+			"attempts": "3",
+			"id":       "reserved",
+			"traits":   map[string]interface{}{"email": "foo@ory.sh"},
+		}, actual.Details())
+		assert.True(t, errors.Is(actual, ErrNotFound().WithID("user_not_found")))
+	})
+
+	t.Run("case=reason without ID", func(t *testing.T) {
+		s := ErrBadRequest().WithReason("reason").GRPCStatus()
+		require.Len(t, s.Details(), 1)
+		info, ok := s.Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		assert.Equal(t, "reason", info.Reason)
+		assert.Empty(t, info.Metadata)
+
+		actual := FromGRPCStatus(s)
+		assert.Empty(t, actual.ID())
+		assert.Equal(t, "reason", actual.Reason())
+	})
+
+	t.Run("case=status code", func(t *testing.T) {
+		s := ErrConflict().WithDomain("kratos.ory.sh").GRPCStatus()
+		require.Len(t, s.Details(), 2)
+		info, ok := s.Details()[1].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		assert.Equal(t, ErrorInfoDomain, info.Domain)
+		assert.Equal(t, map[string]string{"status_code": "409"}, info.Metadata)
+
+		actual := FromGRPCStatus(s)
+		assert.Equal(t, 409, actual.StatusCode())
+		assert.Equal(t, "kratos.ory.sh", actual.Domain())
+		assert.Empty(t, actual.Details())
+	})
+
+	t.Run("case=foreign status code is ignored", func(t *testing.T) {
+		s, err := status.New(codes.FailedPrecondition, "conflict").WithDetails(&errdetails.ErrorInfo{
+			Reason:   "CONFLICT",
+			Domain:   "example.com",
+			Metadata: map[string]string{"status_code": "200"},
+		})
+		require.NoError(t, err)
+
+		actual := FromGRPCStatus(s)
+		assert.Equal(t, 400, actual.StatusCode())
+		assert.Equal(t, map[string]interface{}{"status_code": "200"}, actual.Details())
+	})
+
+	t.Run("case=no error info", func(t *testing.T) {
+		assert.Empty(t, ErrBadRequest().GRPCStatus().Details())
+	})
+}
@@ -4,10 +4,15 @@
 package herodot
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 )
 
 type stackTracer interface {
@@ -21,3 +26,72 @@ var _ ErrorReporter = (*stdReporter)(nil)
 func (s *stdReporter) ReportError(r *http.Request, code int, err error, args ...interface{}) {
 	fmt.Printf("ERROR: %s\n  Request: %v\n  Response Code: %d\n  Further Info: %v\n", err, r, code, args)
 }
+
+// ErrorEvent describes an error which occurred while handling a request or call,
+// independent of the transport.
+type ErrorEvent struct {
+	// Err is the error.
+	Err error
+
+	// Method is the full gRPC method name, e.g. "/helloworld.Greeter/SayHello".
+	Method string
+
+	// Peer is the address of the client.
+	Peer string
+
+	// RequestID is the request ID sent by the client, if any.
+	RequestID string
+
+	// Code is the HTTP status code of the error.
+	Code int
+
+	// GRPCCode is the gRPC status code of the error.
+	GRPCCode codes.Code
+
+	// Latency is the time it took to handle the request.
+	Latency time.Duration
+
+	// Metadata is the metadata of the incoming gRPC call.
+	Metadata metadata.MD
+}
+
+// EventReporter reports errors independent of the transport.
+type EventReporter interface {
+	ReportErrorEvent(ctx context.Context, e *ErrorEvent)
+}
+
+// EventReporterFunc is a function implementing EventReporter.
+type EventReporterFunc func(ctx context.Context, e *ErrorEvent)
+
+// ReportErrorEvent implements EventReporter.
+func (f EventReporterFunc) ReportErrorEvent(ctx context.Context, e *ErrorEvent) {
+	f(ctx, e)
+}
+
+// NewEventReporter adapts an ErrorReporter to an EventReporter. As ErrorReporter requires
+// an *http.Request, it is called with a request describing the gRPC call: its path is the
+// method, its remote address the peer, and its headers the metadata.
+func NewEventReporter(r ErrorReporter) EventReporter {
+	if r == nil {
+		r = &stdReporter{}
+	}
+	return EventReporterFunc(func(ctx context.Context, e *ErrorEvent) {
+		req := &http.Request{
+			Method:     http.MethodPost,
+			URL:        &url.URL{Path: e.Method},
+			Proto:      "HTTP/2.0",
+			ProtoMajor: 2,
+			Header:     make(http.Header, len(e.Metadata)),
+			RemoteAddr: e.Peer,
+			RequestURI: e.Method,
+		}
+		for k, v := range e.Metadata {
+			req.Header[http.CanonicalHeaderKey(k)] = v
+		}
+		if e.RequestID != "" {
+			req.Header.Set("X-Request-ID", e.RequestID)
+		}
+		r.ReportError(req.WithContext(ctx), e.Code, e.Err,
+			fmt.Sprintf("An error occurred while handling gRPC call %s (code %s, latency %s)", e.Method, e.GRPCCode, e.Latency))
+	})
+}
@@ -0,0 +1,105 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrorFromResponse reconstructs the error written by a herodot Writer from an HTTP response.
+// It understands the JSON error envelope, AIP-193 errors, RFC 9457 problem details and plain text bodies.
+//
+// It returns nil if the response's status code is below 400. Otherwise, it returns a *DefaultError
+// whose status code and gRPC code are taken from the response, so that e.g.
+// `errors.Is(err, herodot.ErrNotFound())` works across service boundaries.
+//
+// The response body is consumed and replaced, so that it can be read again.
+func ErrorFromResponse(res *http.Response) error {
+	if res.StatusCode < 400 {
+		return nil
+	}
+
+	de := new(DefaultError)
+	if res.Body != nil {
+		body, err := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			de.Wrap(errors.WithStack(err))
+		} else {
+			decodeErrorBody(de, res.Header.Get("Content-Type"), body)
+		}
+	}
+
+	if de.CodeField == 0 {
+		de.CodeField = res.StatusCode
+	}
+	if de.StatusField == "" {
+		de.StatusField = http.StatusText(de.CodeField)
+	}
+	if de.ErrorField == "" {
+		de.ErrorField = de.StatusField
+	}
+	if de.GRPCCodeField == 0 {
+		de.GRPCCodeField = GRPCCodeFromHTTPStatus(de.CodeField)
+	}
+	if de.IDField == "" {
+		de.IDField = res.Header.Get("Ory-Error-Id")
+	}
+	if de.RIDField == "" {
+		de.RIDField = res.Header.Get("X-Request-ID")
+	}
+	parseRetryHeaders(de, res.Header)
+
+	return de
+}
+
+func decodeErrorBody(de *DefaultError, contentType string, body []byte) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == "application/problem+json":
+		var p Problem
+		if err := json.Unmarshal(body, &p); err != nil {
+			de.Wrap(errors.WithStack(err))
+			return
+		}
+		de.IDField = p.ID
+		de.CodeField = p.Status
+		de.ErrorField = p.Detail
+		de.ReasonField = p.Reason
+		de.RIDField = p.Request
+		de.DomainField = p.Domain
+		de.DetailsField = p.Details
+		de.FieldViolationsField = p.FieldViolations
+		de.PreconditionViolationsField = p.PreconditionViolations
+		de.QuotaViolationsField = p.QuotaViolations
+		de.ResourceInfoField = p.ResourceInfo
+		de.HelpLinksField = p.HelpLinks
+		de.LocalizedMessageField = p.LocalizedMessage
+		de.WarningsField = p.Warnings
+		de.DebugField = p.Debug
+		de.StackField = p.Stack
+		de.CausesField = p.Causes
+		if p.Type != ProblemTypeBlank {
+			de.StatusField = p.Title
+		}
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if err := json.Unmarshal(body, &AIP193ErrorContainer{Error: de}); err == nil {
+			return
+		}
+		if err := json.Unmarshal(body, &ErrorContainer{Error: de}); err != nil {
+			de.Wrap(errors.WithStack(err))
+		}
+	case strings.HasPrefix(mediaType, "text/plain"):
+		de.ErrorField = strings.TrimSpace(string(body))
+	}
+}
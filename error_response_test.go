@@ -0,0 +1,137 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/ory/herodot/httputil"
+)
+
+func TestErrorFromResponse(t *testing.T) {
+	for k, tc := range []struct {
+		name   string
+		writer Writer
+		err    error
+	}{
+		{name: "json", writer: NewJSONWriter(nil), err: ErrNotFound()},
+		{name: "json/wrapped", writer: NewJSONWriter(nil), err: errors.WithStack(ErrMisconfiguration())},
+		{name: "problem", writer: NewProblemWriter(nil), err: ErrNotFound()},
+		{name: "problem/with id", writer: NewProblemWriter(nil), err: ErrMisconfiguration()},
+	} {
+		t.Run(fmt.Sprintf("case=%d/%s", k, tc.name), func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Header.Set("X-Request-ID", "request-id")
+				tc.writer.WriteError(w, r, tc.err)
+			}))
+			defer ts.Close()
+
+			res, err := http.Get(ts.URL)
+			require.NoError(t, err)
+
+			actual := ErrorFromResponse(res)
+			require.Error(t, actual)
+			assert.ErrorIs(t, actual, errors.Cause(tc.err))
+
+			var de *DefaultError
+			require.ErrorAs(t, actual, &de)
+			assert.Equal(t, "request-id", de.RequestID())
+
+			// The body can be read again.
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.NotEmpty(t, body)
+		})
+	}
+
+	t.Run("case=details and grpc code", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewJSONWriter(nil).WriteError(rec, httptest.NewRequest("GET", "/", nil),
+			ErrBadRequest().WithReason("reason").WithDetail("foo", "bar").WithID("some_id"))
+
+		err := ErrorFromResponse(rec.Result())
+		var de *DefaultError
+		require.ErrorAs(t, err, &de)
+		assert.Equal(t, http.StatusBadRequest, de.StatusCode())
+		assert.Equal(t, codes.InvalidArgument, de.GRPCStatus().Code())
+		assert.Equal(t, "reason", de.Reason())
+		assert.Equal(t, "some_id", de.ID())
+		assert.Equal(t, map[string]interface{}{"foo": "bar"}, de.Details())
+	})
+
+	t.Run("case=problem details", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewProblemWriter(nil).WriteError(rec, httptest.NewRequest("GET", "/", nil),
+			ErrPreconditionFailed().
+				WithDomain("ory.sh").
+				WithPreconditionViolation("TOS", "ory.sh/terms", "Terms of service not accepted").
+				WithQuotaViolation("project:1234", "Too many identities").
+				WithResourceInfo(ResourceInfo{ResourceType: "identity", ResourceName: "1234"}).
+				WithHelpLink("Terms of service", "https://www.ory.sh/tos").
+				WithLocalizedMessage("de", "Nutzungsbedingungen nicht akzeptiert"))
+
+		err := ErrorFromResponse(rec.Result())
+		var de *DefaultError
+		require.ErrorAs(t, err, &de)
+		assert.Equal(t, http.StatusPreconditionFailed, de.StatusCode())
+		assert.Equal(t, "ory.sh", de.Domain())
+		assert.Equal(t, []PreconditionViolation{{Type: "TOS", Subject: "ory.sh/terms", Description: "Terms of service not accepted"}}, de.PreconditionViolations())
+		assert.Equal(t, []QuotaViolation{{Subject: "project:1234", Description: "Too many identities"}}, de.QuotaViolations())
+		assert.Equal(t, &ResourceInfo{ResourceType: "identity", ResourceName: "1234"}, de.ResourceInfo())
+		assert.Equal(t, []HelpLink{{Description: "Terms of service", URL: "https://www.ory.sh/tos"}}, de.HelpLinks())
+		assert.Equal(t, &LocalizedMessage{Locale: "de", Message: "Nutzungsbedingungen nicht akzeptiert"}, de.LocalizedMessage())
+	})
+
+	t.Run("case=plain text", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewTextWriter(nil, "plain").WriteError(rec, httptest.NewRequest("GET", "/", nil), ErrForbidden().WithID("forbidden_id"))
+
+		err := ErrorFromResponse(rec.Result())
+		var de *DefaultError
+		require.ErrorAs(t, err, &de)
+		assert.Equal(t, http.StatusForbidden, de.StatusCode())
+		assert.Equal(t, "The requested action was forbidden", de.Error())
+		assert.Equal(t, "forbidden_id", de.ID())
+		assert.Equal(t, codes.PermissionDenied, de.GRPCStatus().Code())
+	})
+
+	t.Run("case=success", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewJSONWriter(nil).Write(rec, httptest.NewRequest("GET", "/", nil), "foo")
+		assert.NoError(t, ErrorFromResponse(rec.Result()))
+	})
+
+	t.Run("case=transport", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			NewJSONWriter(nil).WriteError(w, r, ErrNotFound())
+		}))
+		defer ts.Close()
+
+		c := &http.Client{Transport: &httputil.ErrorTransport{DecodeError: ErrorFromResponse}}
+		_, err := c.Get(ts.URL)
+		assert.ErrorIs(t, err, ErrNotFound())
+	})
+}
+
+func TestErrorContainerUnmarshalJSON(t *testing.T) {
+	for _, raw := range []string{
+		`{"error":{"code":404,"status":"Not Found","message":"The requested resource could not be found"}}`,
+		`{"code":404,"status":"Not Found","message":"The requested resource could not be found"}`,
+	} {
+		var c ErrorContainer
+		require.NoError(t, json.Unmarshal([]byte(raw), &c))
+		assert.ErrorIs(t, c.Error, ErrNotFound())
+		assert.Equal(t, codes.NotFound, c.Error.GRPCCodeField)
+	}
+}
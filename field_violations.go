@@ -0,0 +1,154 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FieldViolationAdapter extracts field violations from errors of a validation library.
+// It returns nil if err is not understood by the adapter.
+type FieldViolationAdapter func(err error) []FieldViolation
+
+var (
+	fieldViolationAdapters   []*FieldViolationAdapter
+	fieldViolationAdaptersMu sync.RWMutex
+)
+
+// RegisterFieldViolationAdapter registers an adapter which is used to extract field violations
+// from wrapped errors, in addition to the built-in support for protoc-gen-validate. This allows
+// supporting validation libraries without herodot depending on them. For example, protovalidate
+// is supported by registering:
+//
+//	herodot.RegisterFieldViolationAdapter(func(err error) []herodot.FieldViolation {
+//		var ve *protovalidate.ValidationError
+//		if !errors.As(err, &ve) {
+//			return nil
+//		}
+//		return herodot.FieldViolationsFromProto(ve.ToProto())
+//	})
+//
+// It returns a function which unregisters the adapter, e.g. for use in tests.
+func RegisterFieldViolationAdapter(a FieldViolationAdapter) (unregister func()) {
+	fieldViolationAdaptersMu.Lock()
+	defer fieldViolationAdaptersMu.Unlock()
+	registered := &a
+	fieldViolationAdapters = append(fieldViolationAdapters, registered)
+
+	return func() {
+		fieldViolationAdaptersMu.Lock()
+		defer fieldViolationAdaptersMu.Unlock()
+		fieldViolationAdapters = slices.DeleteFunc(slices.Clone(fieldViolationAdapters), func(r *FieldViolationAdapter) bool {
+			return r == registered
+		})
+	}
+}
+
+func adaptFieldViolations(err error) (fvs []FieldViolation) {
+	fieldViolationAdaptersMu.RLock()
+	defer fieldViolationAdaptersMu.RUnlock()
+	for _, a := range fieldViolationAdapters {
+		fvs = append(fvs, (*a)(err)...)
+	}
+	return
+}
+
+// FieldViolationsFromProto converts a buf.validate.Violations or buf.validate.Violation message,
+// as returned by protovalidate, into field violations. Nested field paths are preserved, e.g.
+// `user.emails[0]` or `labels["env"]`. The rule ID is used as the reason.
+//
+// The message is read using protobuf reflection, so herodot does not depend on protovalidate.
+// Other messages yield no violations.
+func FieldViolationsFromProto(m proto.Message) (fvs []FieldViolation) {
+	if m == nil {
+		return nil
+	}
+
+	msg := m.ProtoReflect()
+	switch msg.Descriptor().FullName() {
+	case "buf.validate.Violations":
+		violations, ok := listField(msg, "violations")
+		if !ok {
+			return nil
+		}
+		for i := 0; i < violations.Len(); i++ {
+			fvs = append(fvs, fieldViolationFromProto(violations.Get(i).Message()))
+		}
+	case "buf.validate.Violation":
+		fvs = append(fvs, fieldViolationFromProto(msg))
+	}
+	return
+}
+
+func fieldViolationFromProto(v protoreflect.Message) FieldViolation {
+	fv := FieldViolation{
+		Field:       stringField(v, "field_path"),
+		Description: stringField(v, "message"),
+		Reason:      stringField(v, "rule_id"),
+	}
+	if path, ok := messageField(v, "field"); ok {
+		if elements, ok := listField(path, "elements"); ok && elements.Len() > 0 {
+			fv.Field = fieldPathString(elements)
+		}
+	}
+	return fv
+}
+
+// fieldPathString formats buf.validate.FieldPathElement messages the same way protovalidate does.
+func fieldPathString(elements protoreflect.List) string {
+	var b strings.Builder
+	for i := 0; i < elements.Len(); i++ {
+		el := elements.Get(i).Message()
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(stringField(el, "field_name"))
+
+		oneof := el.Descriptor().Oneofs().ByName("subscript")
+		if oneof == nil {
+			continue
+		}
+		fd := el.WhichOneof(oneof)
+		if fd == nil {
+			continue
+		}
+		switch val := el.Get(fd); fd.Kind() {
+		case protoreflect.StringKind:
+			_, _ = fmt.Fprintf(&b, "[%q]", val.String())
+		default:
+			_, _ = fmt.Fprintf(&b, "[%v]", val.Interface())
+		}
+	}
+	return b.String()
+}
+
+func stringField(m protoreflect.Message, name protoreflect.Name) string {
+	fd := m.Descriptor().Fields().ByName(name)
+	if fd == nil || fd.Kind() != protoreflect.StringKind || fd.IsList() {
+		return ""
+	}
+	return m.Get(fd).String()
+}
+
+func messageField(m protoreflect.Message, name protoreflect.Name) (protoreflect.Message, bool) {
+	fd := m.Descriptor().Fields().ByName(name)
+	if fd == nil || fd.Message() == nil || fd.IsList() || fd.IsMap() || !m.Has(fd) {
+		return nil, false
+	}
+	return m.Get(fd).Message(), true
+}
+
+func listField(m protoreflect.Message, name protoreflect.Name) (protoreflect.List, bool) {
+	fd := m.Descriptor().Fields().ByName(name)
+	if fd == nil || !fd.IsList() || fd.Message() == nil {
+		return nil, false
+	}
+	return m.Get(fd).List(), true
+}
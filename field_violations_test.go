@@ -0,0 +1,166 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// bufValidateFile describes the subset of buf/validate/validate.proto used for violations.
+func bufValidateFile(t *testing.T) protoreflect.FileDescriptor {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string, repeated bool, oneof *int32) *descriptorpb.FieldDescriptorProto {
+		label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		if repeated {
+			label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		}
+		fd := &descriptorpb.FieldDescriptorProto{
+			Name:       proto.String(name),
+			Number:     proto.Int32(number),
+			Type:       typ.Enum(),
+			Label:      label.Enum(),
+			OneofIndex: oneof,
+		}
+		if typeName != "" {
+			fd.TypeName = proto.String(typeName)
+		}
+		return fd
+	}
+	msg := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING
+
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("buf/validate/validate.proto"),
+		Package: proto.String("buf.validate"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name:  proto.String("Violations"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("violations", 1, msg, ".buf.validate.Violation", true, nil)},
+			},
+			{
+				Name: proto.String("Violation"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("field_path", 1, str, "", false, nil),
+					field("rule_id", 2, str, "", false, nil),
+					field("message", 3, str, "", false, nil),
+					field("field", 5, msg, ".buf.validate.FieldPath", false, nil),
+				},
+			},
+			{
+				Name:  proto.String("FieldPath"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("elements", 1, msg, ".buf.validate.FieldPathElement", true, nil)},
+			},
+			{
+				Name: proto.String("FieldPathElement"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("field_name", 2, str, "", false, nil),
+					field("index", 6, descriptorpb.FieldDescriptorProto_TYPE_UINT64, "", false, proto.Int32(0)),
+					field("string_key", 10, str, "", false, proto.Int32(0)),
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: proto.String("subscript")}},
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+	return fd
+}
+
+func TestFieldViolationsFromProto(t *testing.T) {
+	file := bufValidateFile(t)
+	newMessage := func(name protoreflect.Name) *dynamicpb.Message {
+		return dynamicpb.NewMessage(file.Messages().ByName(name))
+	}
+	set := func(m *dynamicpb.Message, name protoreflect.Name, v protoreflect.Value) {
+		m.Set(m.Descriptor().Fields().ByName(name), v)
+	}
+	element := func(name string, subscript protoreflect.Name, v protoreflect.Value) protoreflect.Value {
+		el := newMessage("FieldPathElement")
+		set(el, "field_name", protoreflect.ValueOfString(name))
+		if subscript != "" {
+			set(el, subscript, v)
+		}
+		return protoreflect.ValueOfMessage(el)
+	}
+	violation := func(ruleID, message string, elements ...protoreflect.Value) protoreflect.Value {
+		path := newMessage("FieldPath")
+		list := path.Mutable(path.Descriptor().Fields().ByName("elements")).List()
+		for _, el := range elements {
+			list.Append(el)
+		}
+		v := newMessage("Violation")
+		set(v, "rule_id", protoreflect.ValueOfString(ruleID))
+		set(v, "message", protoreflect.ValueOfString(message))
+		set(v, "field", protoreflect.ValueOfMessage(path))
+		return protoreflect.ValueOfMessage(v)
+	}
+
+	violations := newMessage("Violations")
+	list := violations.Mutable(violations.Descriptor().Fields().ByName("violations")).List()
+	list.Append(violation("string.email", "value must be a valid email address",
+		element("user", "", protoreflect.Value{}),
+		element("emails", "index", protoreflect.ValueOfUint64(1))))
+	list.Append(violation("string.min_len", "value length must be at least 1 characters",
+		element("labels", "string_key", protoreflect.ValueOfString("env"))))
+
+	expected := []FieldViolation{
+		{Field: "user.emails[1]", Description: "value must be a valid email address", Reason: "string.email"},
+		{Field: `labels["env"]`, Description: "value length must be at least 1 characters", Reason: "string.min_len"},
+	}
+	assert.Equal(t, expected, FieldViolationsFromProto(violations))
+	assert.Equal(t, expected[:1], FieldViolationsFromProto(list.Get(0).Message().Interface()))
+	assert.Empty(t, FieldViolationsFromProto(&errdetails.ErrorInfo{}))
+
+	t.Run("case=adapter", func(t *testing.T) {
+		t.Cleanup(RegisterFieldViolationAdapter(func(err error) []FieldViolation {
+			var pe *protoValidationError
+			if !errors.As(err, &pe) {
+				return nil
+			}
+			return FieldViolationsFromProto(pe.violations)
+		}))
+
+		err := ErrBadRequest().WithWrap(&protoValidationError{violations: violations})
+		assert.Equal(t, expected, err.FieldViolations())
+		assert.Equal(t, expected, ToDefaultError(err, "").FieldViolationsField)
+
+		br := new(errdetails.BadRequest)
+		for _, d := range err.GRPCStatus().Details() {
+			if d, ok := d.(*errdetails.BadRequest); ok {
+				br = d
+			}
+		}
+		require.Len(t, br.FieldViolations, 2)
+		assert.Equal(t, "user.emails[1]", br.FieldViolations[0].Field)
+		assert.Equal(t, "string.email", br.FieldViolations[0].Reason)
+		assert.Equal(t, expected, FromGRPCStatus(err.GRPCStatus()).FieldViolations())
+	})
+
+	t.Run("case=unregister", func(t *testing.T) {
+		unregister := RegisterFieldViolationAdapter(func(error) []FieldViolation {
+			return []FieldViolation{{Field: "foo", Description: "bar"}}
+		})
+		err := ErrBadRequest().WithWrap(errors.New("invalid"))
+		assert.Len(t, err.FieldViolations(), 1)
+
+		unregister()
+		assert.Empty(t, err.FieldViolations())
+	})
+}
+
+// protoValidationError mimics protovalidate's ValidationError.
+type protoValidationError struct {
+	violations proto.Message
+}
+
+func (e *protoValidationError) Error() string { return "validation error" }
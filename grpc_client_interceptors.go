@@ -0,0 +1,51 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+)
+
+// UnaryClientErrorInterceptor is a gRPC client-side interceptor that converts status errors
+// returned by Unary RPCs into *DefaultError using FromGRPCStatus.
+func UnaryClientErrorInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return fromGRPCError(invoker(ctx, method, req, reply, cc, opts...))
+}
+
+// StreamClientErrorInterceptor is a gRPC client-side interceptor that converts status errors
+// returned by Streaming RPCs into *DefaultError using FromGRPCStatus.
+func StreamClientErrorInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	s, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, fromGRPCError(err)
+	}
+	return &errorClientStream{ClientStream: s}, nil
+}
+
+type errorClientStream struct {
+	grpc.ClientStream
+}
+
+func (s *errorClientStream) SendMsg(m interface{}) error {
+	return streamError(s.ClientStream.SendMsg(m))
+}
+
+func (s *errorClientStream) RecvMsg(m interface{}) error {
+	return streamError(s.ClientStream.RecvMsg(m))
+}
+
+func (s *errorClientStream) CloseSend() error {
+	return streamError(s.ClientStream.CloseSend())
+}
+
+// streamError converts err, except for io.EOF which signals the end of the stream.
+func streamError(err error) error {
+	if err == io.EOF {
+		return err
+	}
+	return fromGRPCError(err)
+}
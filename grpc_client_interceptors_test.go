@@ -0,0 +1,134 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/ory/herodot/internal"
+)
+
+type erroringGreeter struct {
+	internal.UnimplementedGreeterServer
+	err error
+}
+
+func (g *erroringGreeter) SayHello(context.Context, *internal.HelloRequest) (*internal.HelloReply, error) {
+	return nil, g.err
+}
+
+func TestGRPCClientInterceptors(t *testing.T) {
+	server := &erroringGreeter{}
+	s := grpc.NewServer(grpc.UnaryInterceptor(UnaryErrorUnwrapInterceptor))
+	internal.RegisterGreeterServer(s, server)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	serveErr := &errgroup.Group{}
+	serveErr.Go(func() error {
+		return s.Serve(l)
+	})
+
+	conn, err := grpc.NewClient(l.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientErrorInterceptor),
+		grpc.WithStreamInterceptor(StreamClientErrorInterceptor),
+	)
+	require.NoError(t, err)
+	c := internal.NewGreeterClient(conn)
+
+	t.Run("case=default error", func(t *testing.T) {
+		server.err = errors.WithStack(ErrNotFound().WithReason("reason").WithDebug("debug"))
+		server.err.(interface{ Unwrap() error }).Unwrap().(*DefaultError).RIDField = "request-id"
+
+		_, err := c.SayHello(context.Background(), &internal.HelloRequest{})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrNotFound())
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		var de *DefaultError
+		require.ErrorAs(t, err, &de)
+		assert.Equal(t, http.StatusNotFound, de.StatusCode())
+		assert.Equal(t, "reason", de.Reason())
+		assert.Equal(t, "debug", de.Debug())
+		assert.Equal(t, "request-id", de.RequestID())
+	})
+
+	t.Run("case=status error", func(t *testing.T) {
+		server.err = status.Error(codes.Unavailable, "try again later")
+
+		_, err := c.SayHello(context.Background(), &internal.HelloRequest{})
+		var de *DefaultError
+		require.ErrorAs(t, err, &de)
+		assert.Equal(t, http.StatusServiceUnavailable, de.StatusCode())
+		assert.Equal(t, "try again later", de.Error())
+	})
+
+	t.Run("case=field violations", func(t *testing.T) {
+		s, err := status.New(codes.InvalidArgument, "invalid").WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "name", Description: "must not be empty"}},
+		})
+		require.NoError(t, err)
+		server.err = s.Err()
+
+		_, err = c.SayHello(context.Background(), &internal.HelloRequest{})
+		var de *DefaultError
+		require.ErrorAs(t, err, &de)
+		assert.Equal(t, http.StatusBadRequest, de.StatusCode())
+		assert.Equal(t, s.Proto(), de.GRPCStatus().Proto())
+	})
+
+	t.Run("case=every error constructor survives a round trip", func(t *testing.T) {
+		for _, fn := range errorConstructors {
+			expected := fn()
+			t.Run("case="+expected.Status(), func(t *testing.T) {
+				server.err = fn()
+
+				_, err := c.SayHello(context.Background(), &internal.HelloRequest{})
+				assert.ErrorIs(t, err, expected)
+
+				var de *DefaultError
+				require.ErrorAs(t, err, &de)
+				assert.Equal(t, expected.StatusCode(), de.StatusCode())
+				assert.Equal(t, expected.GRPCCode(), de.GRPCCode())
+				assert.Empty(t, de.Details(), "the status code must not leak into the details")
+			})
+		}
+	})
+
+	t.Run("case=downstream error rendered by JSONWriter", func(t *testing.T) {
+		server.err = ErrForbidden().WithReason("reason")
+
+		// Without the interceptor, the raw status error is returned.
+		raw, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		defer raw.Close()
+		_, err = internal.NewGreeterClient(raw).SayHello(context.Background(), &internal.HelloRequest{})
+		require.Error(t, err)
+
+		rec := httptest.NewRecorder()
+		NewJSONWriter(nil).WriteError(rec, httptest.NewRequest("GET", "/", nil), errors.WithStack(err))
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.JSONEq(t, `{"error":{"code":403,"status":"Forbidden","reason":"reason","message":"The requested action was forbidden"}}`, rec.Body.String())
+	})
+
+	require.NoError(t, conn.Close())
+	s.Stop()
+	require.NoError(t, serveErr.Wait())
+}
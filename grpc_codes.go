@@ -0,0 +1,104 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// grpcCodeToHTTPStatus is the canonical mapping of gRPC codes to HTTP status codes
+// as documented in google/rpc/code.proto, see HTTPStatusFromGRPCCode.
+var grpcCodeToHTTPStatus = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           StatusClientClosedRequest,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// httpStatusToGRPCCode is the canonical mapping of HTTP status codes to gRPC codes. Status
+// codes which are not listed are mapped by their class, see GRPCCodeFromHTTPStatus.
+//
+// It is the inverse of grpcCodeToHTTPStatus wherever google/rpc/code.proto is unambiguous.
+// Status codes without a counterpart in code.proto, or with several, map to the gRPC code of
+// their error constructor so that errors decoded from HTTP responses carry the gRPC code the
+// server would have sent. These deviations are marked below. Because several status codes share
+// a gRPC code, the mapping can not be inverted; DefaultError.GRPCStatus therefore carries the
+// HTTP status code whenever it can not be derived from the gRPC code.
+var httpStatusToGRPCCode = map[int]codes.Code{
+	http.StatusOK:           codes.OK,
+	http.StatusBadRequest:   codes.InvalidArgument,
+	http.StatusUnauthorized: codes.Unauthenticated,
+	http.StatusForbidden:    codes.PermissionDenied,
+	http.StatusNotFound:     codes.NotFound,
+	// Deviation: code.proto has no counterpart, see ErrMethodNotAllowed.
+	http.StatusMethodNotAllowed: codes.Unimplemented,
+	// Deviation: code.proto has no counterpart, see ErrNotAcceptable.
+	http.StatusNotAcceptable: codes.InvalidArgument,
+	// Deviation: code.proto has no counterpart, see ErrRequestTimeout.
+	http.StatusRequestTimeout: codes.DeadlineExceeded,
+	// Deviation: code.proto maps both codes.Aborted and codes.AlreadyExists to 409, see ErrConflict.
+	http.StatusConflict: codes.FailedPrecondition,
+	// Deviation: code.proto has no counterpart, see ErrGone.
+	http.StatusGone: codes.NotFound,
+	// Deviation: code.proto maps codes.FailedPrecondition to 400, see ErrPreconditionFailed.
+	http.StatusPreconditionFailed: codes.FailedPrecondition,
+	// Deviation: code.proto has no counterpart, see ErrRequestEntityTooLarge.
+	http.StatusRequestEntityTooLarge: codes.InvalidArgument,
+	// Deviation: code.proto has no counterpart, see ErrUnsupportedMediaType.
+	http.StatusUnsupportedMediaType:         codes.InvalidArgument,
+	http.StatusRequestedRangeNotSatisfiable: codes.OutOfRange,
+	// Deviation: code.proto has no counterpart, see ErrUnprocessableEntity.
+	http.StatusUnprocessableEntity: codes.InvalidArgument,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	StatusClientClosedRequest:      codes.Canceled,
+	http.StatusInternalServerError: codes.Internal,
+	http.StatusNotImplemented:      codes.Unimplemented,
+	// Deviation: code.proto has no counterpart, see ErrUpstreamError.
+	http.StatusBadGateway:         codes.Unavailable,
+	http.StatusServiceUnavailable: codes.Unavailable,
+	http.StatusGatewayTimeout:     codes.DeadlineExceeded,
+}
+
+// GRPCCodeFromHTTPStatus derives a gRPC code from an HTTP status code. It agrees with the gRPC
+// codes of the error constructors. Other 2xx status codes map to codes.OK, 4xx to
+// codes.FailedPrecondition, 5xx to codes.Internal and everything else to codes.Unknown.
+func GRPCCodeFromHTTPStatus(code int) codes.Code {
+	if c, ok := httpStatusToGRPCCode[code]; ok {
+		return c
+	}
+
+	switch {
+	case code >= 200 && code < 300:
+		return codes.OK
+	case code >= 400 && code < 500:
+		return codes.FailedPrecondition
+	case code >= 500 && code < 600:
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+// HTTPStatusFromGRPCCode derives an HTTP status code from a gRPC code as documented in
+// google/rpc/code.proto. Unknown codes map to 500.
+func HTTPStatusFromGRPCCode(code codes.Code) int {
+	if c, ok := grpcCodeToHTTPStatus[code]; ok {
+		return c
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,140 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+var errorConstructors = []func() *DefaultError{
+	ErrNotFound,
+	ErrUnauthorized,
+	ErrForbidden,
+	ErrInternalServerError,
+	ErrBadRequest,
+	ErrUnsupportedMediaType,
+	ErrConflict,
+	ErrMisconfiguration,
+	ErrUpstreamError,
+	ErrNotAcceptable,
+	ErrUnsupportedVersion,
+	ErrMethodNotAllowed,
+	ErrRequestTimeout,
+	ErrGone,
+	ErrPreconditionFailed,
+	ErrRequestEntityTooLarge,
+	ErrRequestedRangeNotSatisfiable,
+	ErrUnprocessableEntity,
+	ErrTooManyRequests,
+	ErrClientClosedRequest,
+	ErrNotImplemented,
+	ErrServiceUnavailable,
+	ErrGatewayTimeout,
+}
+
+func TestCodeMapping(t *testing.T) {
+	t.Run("case=http to grpc", func(t *testing.T) {
+		for code, expected := range map[int]codes.Code{
+			http.StatusOK:                           codes.OK,
+			http.StatusNoContent:                    codes.OK,
+			http.StatusBadRequest:                   codes.InvalidArgument,
+			http.StatusNotFound:                     codes.NotFound,
+			http.StatusConflict:                     codes.FailedPrecondition,
+			http.StatusTeapot:                       codes.FailedPrecondition,
+			http.StatusRequestedRangeNotSatisfiable: codes.OutOfRange,
+			StatusClientClosedRequest:               codes.Canceled,
+			http.StatusInternalServerError:          codes.Internal,
+			http.StatusBadGateway:                   codes.Unavailable,
+			http.StatusInsufficientStorage:          codes.Internal,
+			http.StatusServiceUnavailable:           codes.Unavailable,
+			0:                                       codes.Unknown,
+		} {
+			assert.Equal(t, expected, GRPCCodeFromHTTPStatus(code), "%d", code)
+		}
+	})
+
+	t.Run("case=grpc to http", func(t *testing.T) {
+		for c := codes.OK; c <= codes.Unauthenticated; c++ {
+			assert.NotZero(t, HTTPStatusFromGRPCCode(c), "%s", c)
+		}
+		assert.Equal(t, http.StatusInternalServerError, HTTPStatusFromGRPCCode(codes.Code(1234)))
+	})
+
+	t.Run("case=tables are inverse where code.proto is unambiguous", func(t *testing.T) {
+		for c, code := range grpcCodeToHTTPStatus {
+			switch c {
+			case codes.Unknown, codes.DataLoss, codes.AlreadyExists, codes.Aborted, codes.FailedPrecondition, codes.OutOfRange:
+				// Several gRPC codes share these status codes.
+				continue
+			}
+			assert.Equal(t, c, GRPCCodeFromHTTPStatus(code), "%s", c)
+		}
+	})
+
+	t.Run("case=deviations from code.proto", func(t *testing.T) {
+		for code, expected := range map[int]struct {
+			grpc codes.Code
+			back int
+		}{
+			http.StatusMethodNotAllowed:      {grpc: codes.Unimplemented, back: http.StatusNotImplemented},
+			http.StatusNotAcceptable:         {grpc: codes.InvalidArgument, back: http.StatusBadRequest},
+			http.StatusRequestTimeout:        {grpc: codes.DeadlineExceeded, back: http.StatusGatewayTimeout},
+			http.StatusConflict:              {grpc: codes.FailedPrecondition, back: http.StatusBadRequest},
+			http.StatusGone:                  {grpc: codes.NotFound, back: http.StatusNotFound},
+			http.StatusPreconditionFailed:    {grpc: codes.FailedPrecondition, back: http.StatusBadRequest},
+			http.StatusRequestEntityTooLarge: {grpc: codes.InvalidArgument, back: http.StatusBadRequest},
+			http.StatusUnsupportedMediaType:  {grpc: codes.InvalidArgument, back: http.StatusBadRequest},
+			http.StatusUnprocessableEntity:   {grpc: codes.InvalidArgument, back: http.StatusBadRequest},
+			http.StatusBadGateway:            {grpc: codes.Unavailable, back: http.StatusServiceUnavailable},
+		} {
+			assert.Equal(t, expected.grpc, GRPCCodeFromHTTPStatus(code), "%d", code)
+			assert.Equal(t, expected.back, HTTPStatusFromGRPCCode(expected.grpc), "%d", code)
+		}
+
+		// code.proto maps these gRPC codes to 409, which maps back to the gRPC code of ErrConflict.
+		assert.Equal(t, http.StatusConflict, HTTPStatusFromGRPCCode(codes.AlreadyExists))
+		assert.Equal(t, http.StatusConflict, HTTPStatusFromGRPCCode(codes.Aborted))
+	})
+}
+
+func TestDefaultErrorDerivesCodes(t *testing.T) {
+	for k, tc := range []struct {
+		err          *DefaultError
+		expectedCode int
+		expectedGRPC codes.Code
+	}{
+		{err: &DefaultError{CodeField: http.StatusNotFound}, expectedCode: http.StatusNotFound, expectedGRPC: codes.NotFound},
+		{err: &DefaultError{GRPCCodeField: codes.Unavailable}, expectedCode: http.StatusServiceUnavailable, expectedGRPC: codes.Unavailable},
+		{err: &DefaultError{CodeField: http.StatusConflict, GRPCCodeField: codes.FailedPrecondition}, expectedCode: http.StatusConflict, expectedGRPC: codes.FailedPrecondition},
+		{err: &DefaultError{}, expectedCode: 0, expectedGRPC: codes.Unknown},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			assert.Equal(t, tc.expectedCode, tc.err.StatusCode())
+			assert.Equal(t, tc.expectedGRPC, tc.err.GRPCCode())
+			assert.Equal(t, tc.expectedGRPC, tc.err.WithReason("reason").GRPCStatus().Code())
+
+			de := ToDefaultError(tc.err, "")
+			assert.Equal(t, tc.expectedGRPC, de.GRPCCodeField)
+		})
+	}
+}
+
+func TestErrorFunctionsCodes(t *testing.T) {
+	for _, fn := range errorConstructors {
+		err := fn()
+		t.Run("case="+err.Status(), func(t *testing.T) {
+			assert.NotZero(t, err.CodeField)
+			assert.NotEqual(t, codes.OK, err.GRPCCodeField)
+			assert.NotEmpty(t, err.StatusField)
+			assert.NotEmpty(t, err.ErrorField)
+			assert.Equal(t, err.GRPCCodeField, err.WithReason("reason").GRPCStatus().Code())
+			assert.Equal(t, err.GRPCCodeField, GRPCCodeFromHTTPStatus(err.CodeField), "the constructor and the status code mapping must agree")
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ory/herodot/httputil"
+)
+
+// localizeGRPCError translates the message of err into the language negotiated from the
+// accept-language metadata of the incoming context. It returns false if err was not translated.
+func localizeGRPCError(ctx context.Context, c Catalog, err error) (de *DefaultError, language string, ok bool) {
+	if err == nil || c == nil {
+		return nil, "", false
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	r := &http.Request{Header: http.Header{"Accept-Language": md.Get("accept-language")}}
+	language = httputil.NegotiateLanguage(r, c.Languages(), "")
+	de, ok = localizeError(c, language, err)
+	return de, language, ok
+}
+
+// UnaryLocalizationInterceptor returns a gRPC server-side interceptor for Unary RPCs which translates
+// the messages of herodot errors using the catalog. The language is negotiated from the accept-language
+// metadata, and the translated message is also added as errdetails.LocalizedMessage to the status.
+func UnaryLocalizationInterceptor(c Catalog) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if de, language, ok := localizeGRPCError(ctx, c, err); ok {
+			_ = grpc.SetHeader(ctx, metadata.Pairs("content-language", language))
+			return resp, de
+		}
+		return resp, err
+	}
+}
+
+// StreamLocalizationInterceptor returns a gRPC server-side interceptor for Streaming RPCs which translates
+// the messages of herodot errors using the catalog. See UnaryLocalizationInterceptor.
+func StreamLocalizationInterceptor(c Catalog) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if de, language, ok := localizeGRPCError(ss.Context(), c, err); ok {
+			_ = ss.SetHeader(metadata.Pairs("content-language", language))
+			return de
+		}
+		return err
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// RecoveryHandlerFunc converts a recovered panic value into the error returned to the client.
+type RecoveryHandlerFunc func(ctx context.Context, p interface{}) error
+
+// recoverGRPCPanic converts and reports the recovered panic value p.
+func recoverGRPCPanic(ctx context.Context, r EventReporter, convert RecoveryHandlerFunc, method string, start time.Time, p interface{}) error {
+	var err error
+	if convert != nil {
+		err = convert(ctx, p)
+	} else {
+		err = PanicError(p)
+	}
+
+	if r != nil {
+		reportGRPCError(ctx, r, method, start, err)
+		// The panic was reported already, which reporting interceptors must not repeat.
+		err = NoLogError(err)
+	}
+	return err
+}
+
+// UnaryRecoveryInterceptor returns a gRPC server-side interceptor for Unary RPCs which recovers panics.
+// The panic is converted into an error using convert, which defaults to PanicError, and reported using
+// r unless it is nil. As the stack of the panic is part of the debug information, add it after
+// UnarySanitizeInterceptor in grpc.ChainUnaryInterceptor so that its errors are sanitized.
+func UnaryRecoveryInterceptor(r EventReporter, convert RecoveryHandlerFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+		defer func() {
+			if p := recover(); p != nil {
+				resp, err = nil, recoverGRPCPanic(ctx, r, convert, info.FullMethod, start, p)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecoveryInterceptor returns a gRPC server-side interceptor for Streaming RPCs which recovers
+// panics. See UnaryRecoveryInterceptor.
+func StreamRecoveryInterceptor(r EventReporter, convert RecoveryHandlerFunc) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := time.Now()
+		defer func() {
+			if p := recover(); p != nil {
+				err = recoverGRPCPanic(ss.Context(), r, convert, info.FullMethod, start, p)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/ory/herodot/internal"
+)
+
+type panickingGreeter struct {
+	internal.UnimplementedGreeterServer
+	value interface{}
+}
+
+func (g *panickingGreeter) SayHello(context.Context, *internal.HelloRequest) (*internal.HelloReply, error) {
+	panic(g.value)
+}
+
+func TestPanicError(t *testing.T) {
+	de := PanicError("something bad happened")
+	assert.Equal(t, ErrInternalServerError().Error(), de.Error())
+	assert.Equal(t, codes.Internal, de.GRPCCode())
+	assert.Contains(t, de.Debug(), "panic: something bad happened")
+	assert.Contains(t, de.Debug(), "TestPanicError")
+	assert.NotEmpty(t, de.StackTrace())
+	assert.EqualError(t, de.Unwrap(), "something bad happened")
+
+	cause := errors.New("cause")
+	assert.ErrorIs(t, PanicError(cause), cause)
+}
+
+func TestRecoveryInterceptors(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []*ErrorEvent
+	)
+	reporter := EventReporterFunc(func(_ context.Context, e *ErrorEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})
+
+	server := &panickingGreeter{value: "something bad happened"}
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		UnarySanitizeInterceptor(nil),
+		UnaryReportingInterceptor(reporter),
+		UnaryRecoveryInterceptor(reporter, nil),
+	))
+	internal.RegisterGreeterServer(s, server)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	serveErr := &errgroup.Group{}
+	serveErr.Go(func() error {
+		return s.Serve(l)
+	})
+	t.Cleanup(func() {
+		s.Stop()
+		require.NoError(t, serveErr.Wait())
+	})
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = internal.NewGreeterClient(conn).SayHello(context.Background(), &internal.HelloRequest{})
+	require.Error(t, err)
+	st := status.Convert(err)
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.Equal(t, ErrInternalServerError().Error(), st.Message())
+	assert.False(t, hasDebugInfo(st))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events, 1, "the panic must be reported exactly once")
+	assert.Equal(t, "/helloworld.Greeter/SayHello", events[0].Method)
+	assert.Equal(t, codes.Internal, events[0].GRPCCode)
+	assert.Contains(t, ToDefaultError(events[0].Err, "").Debug(), "panic: something bad happened")
+
+	t.Run("case=stream with custom conversion", func(t *testing.T) {
+		interceptor := StreamRecoveryInterceptor(nil, func(_ context.Context, p interface{}) error {
+			return ErrServiceUnavailable().WithReasonf("%v", p)
+		})
+		err := interceptor(nil, &contextServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, func(interface{}, grpc.ServerStream) error {
+			panic("shutting down")
+		})
+		st := status.Convert(err)
+		assert.Equal(t, codes.Unavailable, st.Code())
+		assert.Equal(t, "shutting down", FromGRPCStatus(st).Reason())
+
+		assert.NoError(t, interceptor(nil, &contextServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, func(interface{}, grpc.ServerStream) error {
+			return nil
+		}))
+	})
+}
@@ -0,0 +1,62 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// reportGRPCError reports err unless it is nil or was marked using NoLogError.
+func reportGRPCError(ctx context.Context, r EventReporter, method string, start time.Time, err error) {
+	if err == nil || isNoLogError(err) {
+		return
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	e := &ErrorEvent{
+		Err:      err,
+		Method:   method,
+		GRPCCode: status.Convert(unwrapGRPCStatusError(err)).Code(),
+		Latency:  time.Since(start),
+		Metadata: md,
+	}
+	if ids := md.Get("x-request-id"); len(ids) > 0 {
+		e.RequestID = ids[0]
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		e.Peer = p.Addr.String()
+	}
+	e.Code = ToDefaultError(err, e.RequestID).StatusCode()
+
+	r.ReportErrorEvent(ctx, e)
+}
+
+// UnaryReportingInterceptor returns a gRPC server-side interceptor for Unary RPCs which reports all errors
+// except those marked using NoLogError. Add it after interceptors which modify errors, such as
+// UnarySanitizeInterceptor, in grpc.ChainUnaryInterceptor to report the original errors.
+func UnaryReportingInterceptor(r EventReporter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		reportGRPCError(ctx, r, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamReportingInterceptor returns a gRPC server-side interceptor for Streaming RPCs which reports all
+// errors except those marked using NoLogError. See UnaryReportingInterceptor.
+func StreamReportingInterceptor(r EventReporter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		reportGRPCError(ss.Context(), r, info.FullMethod, start, err)
+		return err
+	}
+}
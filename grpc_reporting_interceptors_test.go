@@ -0,0 +1,126 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ory/herodot/internal"
+)
+
+type requestRecordingReporter struct {
+	requests []*http.Request
+	codes    []int
+	args     [][]interface{}
+}
+
+func (r *requestRecordingReporter) ReportError(req *http.Request, code int, _ error, args ...interface{}) {
+	r.requests = append(r.requests, req)
+	r.codes = append(r.codes, code)
+	r.args = append(r.args, args)
+}
+
+func TestReportingInterceptors(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []*ErrorEvent
+	)
+	reporter := EventReporterFunc(func(_ context.Context, e *ErrorEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})
+
+	server := &erroringGreeter{}
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(UnaryErrorUnwrapInterceptor, UnaryReportingInterceptor(reporter)))
+	internal.RegisterGreeterServer(s, server)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	serveErr := &errgroup.Group{}
+	serveErr.Go(func() error {
+		return s.Serve(l)
+	})
+	t.Cleanup(func() {
+		s.Stop()
+		require.NoError(t, serveErr.Wait())
+	})
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	c := internal.NewGreeterClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "request-id")
+
+	server.err = errors.WithStack(ErrNotFound())
+	_, err = c.SayHello(ctx, &internal.HelloRequest{})
+	require.Error(t, err)
+
+	server.err = NoLogError(errors.WithStack(ErrConflict()))
+	_, err = c.SayHello(ctx, &internal.HelloRequest{})
+	require.Error(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events, 1)
+	e := events[0]
+	assert.Equal(t, ErrNotFound().Error(), e.Err.Error())
+	assert.Equal(t, "/helloworld.Greeter/SayHello", e.Method)
+	assert.Equal(t, "request-id", e.RequestID)
+	assert.Equal(t, http.StatusNotFound, e.Code)
+	assert.Equal(t, codes.NotFound, e.GRPCCode)
+	assert.NotEmpty(t, e.Peer)
+	assert.Positive(t, e.Latency)
+	assert.Equal(t, []string{"request-id"}, e.Metadata.Get("x-request-id"))
+}
+
+func TestNewEventReporter(t *testing.T) {
+	reporter := new(requestRecordingReporter)
+	NewEventReporter(reporter).ReportErrorEvent(context.Background(), &ErrorEvent{
+		Err:       ErrNotFound(),
+		Method:    "/helloworld.Greeter/SayHello",
+		Peer:      "127.0.0.1:1234",
+		RequestID: "request-id",
+		Code:      http.StatusNotFound,
+		GRPCCode:  codes.NotFound,
+		Metadata:  metadata.Pairs("user-agent", "grpc-go"),
+	})
+
+	require.Len(t, reporter.requests, 1)
+	r := reporter.requests[0]
+	assert.Equal(t, "/helloworld.Greeter/SayHello", r.URL.Path)
+	assert.Equal(t, "127.0.0.1:1234", r.RemoteAddr)
+	assert.Equal(t, "request-id", r.Header.Get("X-Request-ID"))
+	assert.Equal(t, "grpc-go", r.Header.Get("User-Agent"))
+	assert.Equal(t, http.StatusNotFound, reporter.codes[0])
+	assert.Contains(t, reporter.args[0][0], "NotFound")
+}
+
+func TestNoLogError(t *testing.T) {
+	assert.NoError(t, NoLogError(nil))
+
+	reporter := new(requestRecordingReporter)
+	for _, w := range []Writer{NewJSONWriter(reporter), NewTextWriter(reporter, "plain"), NewProblemWriter(reporter), NewHTMLWriter(reporter)} {
+		rec := httptest.NewRecorder()
+		w.WriteError(rec, httptest.NewRequest("GET", "/", nil), NoLogError(errors.WithStack(ErrNotFound())))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		w.WriteError(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), ErrNotFound(), NoLog())
+	}
+	assert.Empty(t, reporter.requests)
+}
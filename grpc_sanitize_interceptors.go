@@ -0,0 +1,110 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"context"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SanitizeOption configures UnarySanitizeInterceptor and StreamSanitizeInterceptor.
+type SanitizeOption func(*sanitizeOptions)
+
+type sanitizeOptions struct {
+	scrub *ScrubPolicy
+}
+
+// SanitizeScrubPolicy additionally scrubs errors using the policy. Debug-only details are kept
+// for calls which send debug information.
+func SanitizeScrubPolicy(p *ScrubPolicy) SanitizeOption {
+	return func(o *sanitizeOptions) {
+		o.scrub = p
+	}
+}
+
+func newSanitizeOptions(opts []SanitizeOption) *sanitizeOptions {
+	o := new(sanitizeOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// sanitizeGRPCError converts err into the status of a DefaultError. The messages of internal errors
+// are replaced with the generic message of ErrInternalServerError while all their other details are
+// removed, and the error is scrubbed using the policy of the options. The DebugInfo detail is removed
+// unless debug returns true.
+func sanitizeGRPCError(ctx context.Context, err error, debug func(context.Context) bool, o *sanitizeOptions) error {
+	if err == nil {
+		return nil
+	}
+	withDebug := debug != nil && debug(ctx)
+
+	de := ToDefaultError(err, "")
+	switch c := de.GRPCCode(); c {
+	case codes.Internal, codes.Unknown:
+		// Context errors would otherwise surface as internal errors.
+		if s := status.FromContextError(err); s.Code() != codes.Unknown {
+			de.GRPCCodeField = s.Code()
+			break
+		}
+		de.GRPCCodeField = c
+		de.ErrorField = ErrInternalServerError().ErrorField
+		de.LocalizedMessageField = nil
+		de.ReasonField = ""
+		de.DetailsField = nil
+		de.FieldViolationsField = nil
+		de.PreconditionViolationsField = nil
+		de.QuotaViolationsField = nil
+		de.ResourceInfoField = nil
+		de.HelpLinksField = nil
+		// The wrapped errors could carry field violations, so only their stack trace is kept.
+		if st := de.StackTrace(); st != nil {
+			de.err = &scrubbedCause{stack: st}
+		} else {
+			de.err = nil
+		}
+	}
+
+	de = o.scrub.Scrub(de, withDebug)
+
+	p := de.GRPCStatus().Proto()
+	if !withDebug {
+		details := p.Details[:0]
+		for _, d := range p.Details {
+			if !d.MessageIs((*errdetails.DebugInfo)(nil)) {
+				details = append(details, d)
+			}
+		}
+		p.Details = details
+	}
+	return status.ErrorProto(p)
+}
+
+// UnarySanitizeInterceptor returns a gRPC server-side interceptor for Unary RPCs which converts errors
+// into herodot errors and makes them safe to send to clients: the errdetails.DebugInfo detail is removed
+// unless debug returns true for the call, and the messages of codes.Internal and codes.Unknown errors are
+// replaced with the generic message of ErrInternalServerError while all their other details are removed.
+// Pass DebugFromContext as debug to enable debug information for calls whose context was created using
+// ContextWithDebug, or nil to never send it. Use SanitizeScrubPolicy to additionally scrub errors.
+func UnarySanitizeInterceptor(debug func(ctx context.Context) bool, opts ...SanitizeOption) grpc.UnaryServerInterceptor {
+	o := newSanitizeOptions(opts)
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, sanitizeGRPCError(ctx, err, debug, o)
+	}
+}
+
+// StreamSanitizeInterceptor returns a gRPC server-side interceptor for Streaming RPCs which converts errors
+// into herodot errors and makes them safe to send to clients. See UnarySanitizeInterceptor.
+func StreamSanitizeInterceptor(debug func(ctx context.Context) bool, opts ...SanitizeOption) grpc.StreamServerInterceptor {
+	o := newSanitizeOptions(opts)
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return sanitizeGRPCError(ss.Context(), handler(srv, ss), debug, o)
+	}
+}
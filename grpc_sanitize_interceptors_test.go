@@ -0,0 +1,192 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/ory/herodot/internal"
+)
+
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func hasDebugInfo(s *status.Status) bool {
+	for _, d := range s.Details() {
+		if _, ok := d.(*errdetails.DebugInfo); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSanitizeInterceptors(t *testing.T) {
+	server := &erroringGreeter{}
+	s := grpc.NewServer(grpc.UnaryInterceptor(UnarySanitizeInterceptor(nil)))
+	internal.RegisterGreeterServer(s, server)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	serveErr := &errgroup.Group{}
+	serveErr.Go(func() error {
+		return s.Serve(l)
+	})
+	t.Cleanup(func() {
+		s.Stop()
+		require.NoError(t, serveErr.Wait())
+	})
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	c := internal.NewGreeterClient(conn)
+
+	for k, tc := range []struct {
+		err           error
+		expectCode    codes.Code
+		expectMessage string
+	}{
+		{
+			err:           errors.New("dial tcp 10.0.0.1:5432: connection refused"),
+			expectCode:    codes.Internal,
+			expectMessage: ErrInternalServerError().Error(),
+		},
+		{
+			err:           errors.WithStack(ErrInternalServerError().WithError("pq: relation users does not exist").WithDebug("SELECT * FROM users")),
+			expectCode:    codes.Internal,
+			expectMessage: ErrInternalServerError().Error(),
+		},
+		{
+			err:           errors.WithStack(ErrInternalServerError().WithReason("pq: password authentication failed").WithDetail("dsn", "postgres://user:secret@db")),
+			expectCode:    codes.Internal,
+			expectMessage: ErrInternalServerError().Error(),
+		},
+		{
+			err: errors.WithStack(ErrInternalServerError().
+				WithWrap(ErrBadRequest().WithFieldViolation("password", "hunter2 is too short")).
+				WithPreconditionViolation("DB", "postgres://user:secret@db", "migration pending").
+				WithQuotaViolation("pool:users", "connection pool exhausted").
+				WithResourceInfo(ResourceInfo{ResourceType: "table", ResourceName: "users"}).
+				WithHelpLink("Runbook", "https://runbooks.internal/db")),
+			expectCode:    codes.Internal,
+			expectMessage: ErrInternalServerError().Error(),
+		},
+		{
+			err:           status.Error(codes.Unknown, "panic: runtime error"),
+			expectCode:    codes.Unknown,
+			expectMessage: ErrInternalServerError().Error(),
+		},
+		{
+			err:           errors.WithStack(ErrNotFound().WithDebug("sql: no rows")),
+			expectCode:    codes.NotFound,
+			expectMessage: ErrNotFound().Error(),
+		},
+		{
+			err:           errors.WithStack(context.DeadlineExceeded),
+			expectCode:    codes.DeadlineExceeded,
+			expectMessage: "context deadline exceeded",
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			server.err = tc.err
+			_, err := c.SayHello(context.Background(), &internal.HelloRequest{})
+			require.Error(t, err)
+
+			st := status.Convert(err)
+			assert.Equal(t, tc.expectCode, st.Code())
+			assert.Equal(t, tc.expectMessage, st.Message())
+			assert.False(t, hasDebugInfo(st))
+			assert.Empty(t, FromGRPCStatus(st).Reason())
+			assert.Empty(t, FromGRPCStatus(st).Details())
+			if tc.expectCode == codes.Internal || tc.expectCode == codes.Unknown {
+				assert.Empty(t, st.Details())
+			}
+		})
+	}
+
+	t.Run("case=stream with debug", func(t *testing.T) {
+		interceptor := StreamSanitizeInterceptor(DebugFromContext)
+		handler := func(interface{}, grpc.ServerStream) error {
+			return errors.WithStack(ErrNotFound().WithDebug("sql: no rows"))
+		}
+
+		err := interceptor(nil, &contextServerStream{ctx: ContextWithDebug(context.Background(), true)}, &grpc.StreamServerInfo{}, handler)
+		st := status.Convert(err)
+		assert.Equal(t, codes.NotFound, st.Code())
+		assert.True(t, hasDebugInfo(st))
+		assert.Equal(t, "sql: no rows", FromGRPCStatus(st).Debug())
+
+		err = interceptor(nil, &contextServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, handler)
+		assert.False(t, hasDebugInfo(status.Convert(err)))
+
+		assert.NoError(t, interceptor(nil, &contextServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, func(interface{}, grpc.ServerStream) error { return nil }))
+	})
+}
+
+func TestSanitizeInterceptorsScrubPolicy(t *testing.T) {
+	unary := UnarySanitizeInterceptor(DebugFromContext, SanitizeScrubPolicy(testScrubPolicy()))
+	call := func(ctx context.Context, err error) *status.Status {
+		_, actual := unary(ctx, nil, &grpc.UnaryServerInfo{}, func(context.Context, interface{}) (interface{}, error) {
+			return nil, err
+		})
+		s, ok := status.FromError(actual)
+		require.True(t, ok)
+		return s
+	}
+
+	s := call(context.Background(), errors.WithStack(ErrNotFound().WithReason("user foo@example.com does not exist")))
+	assert.Equal(t, "user [email] does not exist", FromGRPCStatus(s).Reason())
+
+	actual := StreamSanitizeInterceptor(nil, SanitizeScrubPolicy(testScrubPolicy()))(nil, &contextServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, func(interface{}, grpc.ServerStream) error {
+		return errors.New("could not reach foo@example.com")
+	})
+	assert.Equal(t, "Something went wrong", status.Convert(actual).Message())
+
+	_, err := unary(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+
+	t.Run("case=debug only details", func(t *testing.T) {
+		err := ErrNotFound().WithID("user_not_found").WithDetail("query", "SELECT * FROM users")
+		assert.Empty(t, FromGRPCStatus(call(context.Background(), err)).Details())
+		assert.Equal(t, map[string]interface{}{"query": "SELECT * FROM users"},
+			FromGRPCStatus(call(ContextWithDebug(context.Background(), true), err)).Details())
+	})
+
+	t.Run("case=debug info is only sent with debug and kept unless a rule omits it", func(t *testing.T) {
+		debug := ContextWithDebug(context.Background(), true)
+
+		assert.False(t, hasDebugInfo(call(context.Background(), errors.WithStack(ErrNotFound()))))
+		assert.False(t, hasDebugInfo(call(debug, errors.WithStack(ErrInternalServerError().WithDebug("database is down")))))
+
+		s := call(debug, errors.WithStack(ErrNotFound().WithDebug("sql: no rows")))
+		require.True(t, hasDebugInfo(s))
+		assert.Equal(t, "sql: no rows", FromGRPCStatus(s).Debug())
+		for _, d := range s.Details() {
+			if d, ok := d.(*errdetails.DebugInfo); ok {
+				assert.NotEmpty(t, d.StackEntries)
+			}
+		}
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"errors"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// FromGRPCStatus converts a gRPC status, e.g. one created by DefaultError.GRPCStatus,
+// back into a *DefaultError. The HTTP status code is restored from the ErrorInfo detail of
+// the domain ErrorInfoDomain or, if it is not carried, derived from the gRPC code. All other
+// fields except for the headers are restored from the status details.
+// It returns nil if the status is nil or its code is codes.OK.
+func FromGRPCStatus(s *status.Status) *DefaultError {
+	if s == nil || s.Code() == codes.OK {
+		return nil
+	}
+
+	de := new(DefaultError)
+	de.applyGRPCStatus(s)
+	return de
+}
+
+// applyGRPCStatus sets the code, message and all fields carried in the status details.
+func (e *DefaultError) applyGRPCStatus(s *status.Status) {
+	e.CodeField = HTTPStatusFromGRPCCode(s.Code())
+	e.GRPCCodeField = s.Code()
+	e.ErrorField = s.Message()
+
+	for _, detail := range s.Details() {
+		switch d := detail.(type) {
+		case *errdetails.DebugInfo:
+			e.DebugField = d.GetDetail()
+		case *errdetails.ErrorInfo:
+			if isStatusCodeInfo(d) {
+				e.applyStatusCodeInfo(d)
+				continue
+			}
+			e.applyErrorInfo(d)
+		case *structpb.Struct:
+			e.applyDetailsStruct(d)
+		case *errdetails.RequestInfo:
+			e.RIDField = d.GetRequestId()
+		case *errdetails.RetryInfo:
+			e.retryAfter = d.GetRetryDelay().AsDuration()
+		case *errdetails.QuotaFailure:
+			for _, v := range d.GetViolations() {
+				// Only the violation of the rate limit created by retryDetails carries the reset.
+				reset, ok := v.GetQuotaDimensions()[quotaDimensionReset]
+				if !ok {
+					e.QuotaViolationsField = append(e.QuotaViolationsField, QuotaViolation{
+						Subject:     v.GetSubject(),
+						Description: v.GetDescription(),
+					})
+					continue
+				}
+				e.rateLimit = &RateLimit{
+					Limit:   int(v.GetQuotaValue()),
+					Policy:  v.GetQuotaId(),
+					Subject: v.GetSubject(),
+				}
+				e.rateLimit.Reset, _ = time.ParseDuration(reset)
+				e.rateLimit.Window, _ = time.ParseDuration(v.GetQuotaDimensions()[quotaDimensionWindow])
+			}
+		case *errdetails.ResourceInfo:
+			e.ResourceInfoField = &ResourceInfo{
+				ResourceType: d.GetResourceType(),
+				ResourceName: d.GetResourceName(),
+				Owner:        d.GetOwner(),
+				Description:  d.GetDescription(),
+			}
+		case *errdetails.PreconditionFailure:
+			for _, v := range d.GetViolations() {
+				e.PreconditionViolationsField = append(e.PreconditionViolationsField, PreconditionViolation{
+					Type:        v.GetType(),
+					Subject:     v.GetSubject(),
+					Description: v.GetDescription(),
+				})
+			}
+		case *errdetails.Help:
+			for _, l := range d.GetLinks() {
+				e.HelpLinksField = append(e.HelpLinksField, HelpLink{
+					Description: l.GetDescription(),
+					URL:         l.GetUrl(),
+				})
+			}
+		case *errdetails.LocalizedMessage:
+			e.LocalizedMessageField = &LocalizedMessage{
+				Locale:  d.GetLocale(),
+				Message: d.GetMessage(),
+			}
+		case *errdetails.BadRequest:
+			for _, fv := range d.GetFieldViolations() {
+				e.FieldViolationsField = append(e.FieldViolationsField, FieldViolation{
+					Field:       fv.GetField(),
+					Description: fv.GetDescription(),
+					Reason:      fv.GetReason(),
+				})
+			}
+		}
+	}
+
+	e.StatusField = statusText(e.CodeField)
+}
+
+// fromGRPCError converts status errors into *DefaultError and returns all other errors as they are.
+func fromGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*DefaultError); ok {
+		return err
+	}
+	if c := grpcStatusError(nil); errors.As(err, &c) {
+		if de := FromGRPCStatus(c.GRPCStatus()); de != nil {
+			return de
+		}
+	}
+	return err
+}
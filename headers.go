@@ -0,0 +1,134 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// deniedErrorHeaders lists the headers errors can not set because they are
+// either managed by the writers or relevant for security.
+var deniedErrorHeaders = []string{
+	"Access-Control-Allow-Credentials",
+	"Access-Control-Allow-Headers",
+	"Access-Control-Allow-Methods",
+	"Access-Control-Allow-Origin",
+	"Access-Control-Expose-Headers",
+	"Connection",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Length",
+	"Content-Security-Policy",
+	"Content-Type",
+	"Deprecation",
+	"Ory-Error-Id",
+	"Set-Cookie",
+	"Strict-Transport-Security",
+	"Sunset",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+	"X-Content-Type-Options",
+	"X-Frame-Options",
+}
+
+// HeadersCarrier can be implemented by an error to set response headers, e.g.
+// WWW-Authenticate or Allow. Headers managed by the writers, such as Content-Type or
+// Deprecation, and security relevant headers, such as Set-Cookie or the CORS headers, are
+// ignored. Values of the Vary and Link headers are added to those set by the writers.
+type HeadersCarrier interface {
+	// Headers returns the response headers for the error, if applicable.
+	Headers() http.Header
+}
+
+// Headers returns the response headers.
+func (e *DefaultError) Headers() http.Header {
+	return e.headers
+}
+
+// WithHeader adds a response header. Mutates and returns the receiver.
+func (e *DefaultError) WithHeader(key, value string) *DefaultError {
+	if e.headers == nil {
+		e.headers = http.Header{}
+	} else {
+		e.headers = e.headers.Clone()
+	}
+	e.headers.Add(key, value)
+	return e
+}
+
+// collectHeaders collects the headers of all HeadersCarrier in the error's chain.
+// Headers of outer errors take precedence over headers of the errors they wrap.
+func collectHeaders(err error) http.Header {
+	var headers http.Header
+	walkErrors(err, func(err error) {
+		c, ok := err.(HeadersCarrier)
+		if !ok {
+			return
+		}
+		for k, v := range c.Headers() {
+			k = http.CanonicalHeaderKey(k)
+			if _, ok := headers[k]; ok || isDeniedErrorHeader(k) {
+				continue
+			}
+			if headers == nil {
+				headers = http.Header{}
+			}
+			headers[k] = append([]string(nil), v...)
+		}
+	})
+	return headers
+}
+
+// walkErrors calls fn for err and every error in its chain, depth first.
+func walkErrors(err error, fn func(error)) {
+	for err != nil {
+		fn(err)
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			next := e.Unwrap()
+			// Comparing errors of a type which is not comparable panics.
+			if reflect.TypeOf(err).Comparable() && next == err {
+				return
+			}
+			err = next
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walkErrors(err, fn)
+			}
+			return
+		default:
+			return
+		}
+	}
+}
+
+func isDeniedErrorHeader(key string) bool {
+	for _, denied := range deniedErrorHeaders {
+		if http.CanonicalHeaderKey(denied) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// setErrorHeaders sets the headers carried by err, including retry information.
+func setErrorHeaders(h http.Header, err error) {
+	for k, v := range collectHeaders(err) {
+		if k == "Vary" {
+			for _, v := range v {
+				for _, field := range strings.Split(v, ",") {
+					if field = strings.TrimSpace(field); field != "" {
+						addVary(h, field)
+					}
+				}
+			}
+			continue
+		}
+		h[k] = v
+	}
+	setRetryHeaders(h, err)
+}
@@ -0,0 +1,111 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type headerError struct {
+	error
+	headers http.Header
+}
+
+func (e *headerError) Headers() http.Header { return e.headers }
+func (e *headerError) Unwrap() error        { return e.error }
+
+// sliceError is not comparable.
+type sliceError struct {
+	error
+	trace []string
+}
+
+func (e sliceError) Unwrap() error { return e.error }
+
+func TestErrorHeaders(t *testing.T) {
+	for k, w := range []Writer{
+		NewJSONWriter(nil),
+		NewTextWriter(nil, "plain"),
+		NewProblemWriter(nil),
+		NewHTMLWriter(nil),
+		NewNegotiationHandler(nil),
+	} {
+		t.Run(fmt.Sprintf("case=%d/%T", k, w), func(t *testing.T) {
+			err := errors.WithStack(ErrUnauthorized().
+				WithHeader("WWW-Authenticate", `Bearer realm="example"`).
+				WithHeader("Set-Cookie", "session=evil").
+				WithHeader("Content-Type", "text/evil").
+				WithHeader("Content-Language", "evil").
+				WithHeader("Deprecation", "@0").
+				WithHeader("Sunset", "Thu, 01 Jan 1970 00:00:00 GMT"))
+
+			rec := httptest.NewRecorder()
+			w.WriteError(rec, httptest.NewRequest("GET", "/", nil), err)
+
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Equal(t, `Bearer realm="example"`, rec.Header().Get("WWW-Authenticate"))
+			assert.Empty(t, rec.Header().Values("Set-Cookie"))
+			assert.NotEqual(t, "text/evil", rec.Header().Get("Content-Type"))
+			assert.Empty(t, rec.Header().Values("Content-Language"))
+			assert.Empty(t, rec.Header().Values("Deprecation"))
+			assert.Empty(t, rec.Header().Values("Sunset"))
+		})
+	}
+}
+
+func TestErrorHeadersMergeVary(t *testing.T) {
+	h := NewJSONWriter(nil)
+	h.Catalog = NewMessageCatalog()
+
+	rec := httptest.NewRecorder()
+	h.WriteError(rec, httptest.NewRequest("GET", "/", nil), ErrBadRequest().WithHeader("Vary", "Cookie, accept-language"))
+
+	assert.Equal(t, []string{"Accept-Language", "Cookie"}, rec.Header().Values("Vary"))
+}
+
+func TestErrorHeadersMergeLink(t *testing.T) {
+	r := WithDeprecation(httptest.NewRequest("GET", "/", nil), Deprecation{Since: time.Unix(1, 0), Link: "https://www.ory.sh/migrate"})
+	rec := httptest.NewRecorder()
+	NewJSONWriter(nil).WriteError(rec, r, ErrGone().WithHeader("Link", `<https://www.ory.sh/v2>; rel="successor-version"`))
+
+	assert.Equal(t, []string{
+		`<https://www.ory.sh/v2>; rel="successor-version"`,
+		`<https://www.ory.sh/migrate>; rel="deprecation"`,
+	}, rec.Header().Values("Link"))
+}
+
+func TestCollectHeaders(t *testing.T) {
+	inner := ErrMethodNotAllowed().
+		WithHeader("Allow", "GET").
+		WithHeader("X-Inner", "a").
+		WithHeader("X-Inner", "b")
+	err := &headerError{
+		error:   errors.WithStack(inner),
+		headers: http.Header{"Allow": {"GET, POST"}, "set-cookie": {"foo=bar"}},
+	}
+
+	assert.Equal(t, http.Header{
+		"Allow":   {"GET, POST"},
+		"X-Inner": {"a", "b"},
+	}, collectHeaders(err))
+	assert.Equal(t, collectHeaders(err), ToDefaultError(err, "").Headers())
+
+	t.Run("case=clone does not share headers", func(t *testing.T) {
+		c := inner.Clone().WithHeader("X-Clone", "c")
+		assert.Empty(t, inner.Headers().Get("X-Clone"))
+		assert.Equal(t, "c", c.Headers().Get("X-Clone"))
+	})
+
+	t.Run("case=errors which are not comparable", func(t *testing.T) {
+		err := sliceError{error: sliceError{error: inner, trace: []string{"b"}}, trace: []string{"a"}}
+		assert.Equal(t, inner.Headers(), collectHeaders(err))
+	})
+}
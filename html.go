@@ -0,0 +1,305 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultHTMLContentSecurityPolicy is the Content-Security-Policy sent with HTML error pages.
+// It only allows the inline styles used by the built-in error page.
+const DefaultHTMLContentSecurityPolicy = "default-src 'none'; style-src 'unsafe-inline'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+
+// DefaultHTMLTemplate renders the response object of Write, WriteCode and WriteCreated.
+var DefaultHTMLTemplate = template.Must(template.New("response").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+<pre>{{ printf "%v" . }}</pre>
+</body>
+</html>
+`))
+
+// DefaultHTMLErrorTemplate renders errors. It is executed with an *HTMLErrorPage.
+var DefaultHTMLErrorTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html lang="{{ .Language }}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{ .Code }} {{ .Error.Status }}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, Helvetica, Arial, sans-serif; color: #1f2937; max-width: 40rem; margin: 4rem auto; padding: 0 1rem; line-height: 1.5; }
+h1 { font-size: 1.5rem; }
+dt { font-weight: 600; }
+dd { margin: 0 0 .75rem 0; word-break: break-all; }
+pre { background: #f3f4f6; padding: 1rem; overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>{{ .Code }} {{ .Error.Status }}</h1>
+<p>{{ .Error.Error }}</p>
+{{- with .Error.Reason }}
+<p>{{ . }}</p>
+{{- end }}
+<dl>
+{{- with .Error.ID }}
+<dt>Error ID</dt>
+<dd><code>{{ . }}</code></dd>
+{{- end }}
+{{- with .Error.RequestID }}
+<dt>Request ID</dt>
+<dd><code>{{ . }}</code></dd>
+{{- end }}
+</dl>
+{{- with .Error.Details }}
+<h2>Details</h2>
+<dl>
+{{- range $key, $value := . }}
+<dt>{{ $key }}</dt>
+<dd><code>{{ $value }}</code></dd>
+{{- end }}
+</dl>
+{{- end }}
+{{- with .Error.Warnings }}
+<h2>Warnings</h2>
+<ul>
+{{- range . }}
+<li>{{ . }}</li>
+{{- end }}
+</ul>
+{{- end }}
+{{- if .Debug }}
+{{- with .Error.Debug }}
+<h2>Debug</h2>
+<pre>{{ . }}</pre>
+{{- end }}
+{{- with .Error.Causes }}
+<h2>Causes</h2>
+<ol>
+{{- range . }}
+<li><code>{{ .Type }}</code>: {{ .Message }}</li>
+{{- end }}
+</ol>
+{{- end }}
+{{- with .Error.Stack }}
+<h2>Stack</h2>
+<pre>
+{{- range . }}
+{{ .Function }}
+	{{ .File }}:{{ .Line }}
+{{- end }}
+</pre>
+{{- end }}
+{{- end }}
+</body>
+</html>
+`))
+
+// HTMLErrorPage is the data HTML error templates are executed with.
+type HTMLErrorPage struct {
+	// Code is the HTTP status code of the response.
+	Code int
+
+	// Error is the error to render. Its debug information is only
+	// set if debug output is enabled.
+	Error *DefaultError
+
+	// Debug is true if debug output is enabled.
+	Debug bool
+
+	// Language is the language of the error message, which is "en" unless
+	// the message was translated using the writer's Catalog.
+	Language string
+}
+
+// HTMLWriter writes HTML responses rendered with html/template, which escapes
+// all values and thus protects against cross-site scripting.
+type HTMLWriter struct {
+	Reporter    ErrorReporter
+	EnableDebug bool
+
+	// DebugPolicy, if set, enables debug output for individual requests
+	// even if EnableDebug is false.
+	DebugPolicy DebugPolicy
+
+	// ScrubPolicy, if set, scrubs errors before they are written.
+	ScrubPolicy *ScrubPolicy
+
+	// DebugTrace configures the stack trace and causes added to errors
+	// if debug output is enabled.
+	DebugTrace DebugTraceOptions
+
+	// Template renders responses written with Write, WriteCode and WriteCreated.
+	// It is executed with the response object.
+	Template *template.Template
+
+	// ErrorTemplate renders errors for which no error ID or status code specific
+	// template is registered. It is executed with an *HTMLErrorPage.
+	ErrorTemplate *template.Template
+
+	// ContentSecurityPolicy is sent with error pages.
+	ContentSecurityPolicy string
+
+	// Catalog, if set, translates error messages into the language
+	// negotiated from the Accept-Language header.
+	Catalog Catalog
+
+	codeTemplates map[int]*template.Template
+	idTemplates   map[string]*template.Template
+}
+
+var _ Writer = (*HTMLWriter)(nil)
+
+// NewHTMLWriter returns a writer for HTML pages using the default templates.
+func NewHTMLWriter(reporter ErrorReporter) *HTMLWriter {
+	writer := &HTMLWriter{
+		Reporter:              reporter,
+		Template:              DefaultHTMLTemplate,
+		ErrorTemplate:         DefaultHTMLErrorTemplate,
+		ContentSecurityPolicy: DefaultHTMLContentSecurityPolicy,
+	}
+	if writer.Reporter == nil {
+		writer.Reporter = &stdReporter{}
+	}
+	return writer
+}
+
+// WithStatusCodeTemplate registers the template used to render errors with the given status code.
+// Mutates and returns the receiver.
+func (h *HTMLWriter) WithStatusCodeTemplate(code int, t *template.Template) *HTMLWriter {
+	if h.codeTemplates == nil {
+		h.codeTemplates = map[int]*template.Template{}
+	}
+	h.codeTemplates[code] = t
+	return h
+}
+
+// WithErrorIDTemplate registers the template used to render errors with the given error ID.
+// Error ID templates take precedence over status code templates. Mutates and returns the receiver.
+func (h *HTMLWriter) WithErrorIDTemplate(id string, t *template.Template) *HTMLWriter {
+	if h.idTemplates == nil {
+		h.idTemplates = map[string]*template.Template{}
+	}
+	h.idTemplates[id] = t
+	return h
+}
+
+func (h *HTMLWriter) errorTemplate(code int, id string) *template.Template {
+	if t, ok := h.idTemplates[id]; ok && id != "" {
+		return t
+	}
+	if t, ok := h.codeTemplates[code]; ok {
+		return t
+	}
+	if h.ErrorTemplate != nil {
+		return h.ErrorTemplate
+	}
+	return DefaultHTMLErrorTemplate
+}
+
+// Write a response object to the ResponseWriter with status code 200.
+func (h *HTMLWriter) Write(w http.ResponseWriter, r *http.Request, e interface{}, _ ...EncoderOptions) {
+	h.WriteCode(w, r, http.StatusOK, e)
+}
+
+// WriteCode writes a response object to the ResponseWriter and sets a response code.
+func (h *HTMLWriter) WriteCode(w http.ResponseWriter, r *http.Request, code int, e interface{}, _ ...EncoderOptions) {
+	t := h.Template
+	if t == nil {
+		t = DefaultHTMLTemplate
+	}
+
+	bs := new(bytes.Buffer)
+	if err := t.Execute(bs, e); err != nil {
+		h.WriteError(w, r, errors.WithStack(err))
+		return
+	}
+
+	if code == 0 {
+		code = http.StatusOK
+	}
+
+	if errors.Is(r.Context().Err(), context.Canceled) {
+		code = StatusClientClosedRequest
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	setDeprecationHeaders(w.Header(), r, h.Reporter)
+	w.WriteHeader(code)
+	_, _ = w.Write(bs.Bytes())
+}
+
+// WriteCreated writes a response object to the ResponseWriter with status code 201 and
+// the Location header set to location.
+func (h *HTMLWriter) WriteCreated(w http.ResponseWriter, r *http.Request, location string, e interface{}) {
+	w.Header().Set("Location", location)
+	h.WriteCode(w, r, http.StatusCreated, e)
+}
+
+// WriteError writes an error to ResponseWriter and tries to extract the error's status code by
+// asserting statusCodeCarrier. If the error does not implement statusCodeCarrier, the status code
+// is set to 500.
+func (h *HTMLWriter) WriteError(w http.ResponseWriter, r *http.Request, err error, opts ...Option) {
+	h.WriteErrorCode(w, r, statusCodeOf(err), err, opts...)
+}
+
+// WriteErrorCode writes an error to ResponseWriter and forces an error code.
+func (h *HTMLWriter) WriteErrorCode(w http.ResponseWriter, r *http.Request, code int, err error, opts ...Option) {
+	o := newOptions(opts)
+	err = coalesceError(err)
+
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+
+	if errors.Is(r.Context().Err(), context.Canceled) {
+		code = StatusClientClosedRequest
+	}
+
+	if !o.noLog && !isNoLogError(err) {
+		// All errors land here, so it's a really good idea to do the logging here as well!
+		h.Reporter.ReportError(r, code, err, "An error occurred while handling a request")
+	}
+	err = localizeResponse(w, r, h.Catalog, err)
+
+	debug := debugEnabled(r, h.EnableDebug, h.DebugPolicy)
+	de := debugError(ToDefaultError(err, r.Header.Get("X-Request-ID")), debug, h.DebugTrace)
+	de.WarningsField = withDeprecationWarning(r, de.WarningsField)
+	de = h.ScrubPolicy.Scrub(de, debug)
+
+	page := &HTMLErrorPage{Code: code, Error: de, Debug: debug, Language: "en"}
+	if language := w.Header().Get("Content-Language"); language != "" {
+		page.Language = language
+	}
+	bs := new(bytes.Buffer)
+	if err := h.errorTemplate(code, de.ID()).Execute(bs, page); err != nil {
+		h.Reporter.ReportError(r, code, errors.WithStack(err), "Could not render HTML error template")
+
+		// Fall back to the built-in template which is known to work.
+		bs.Reset()
+		_ = DefaultHTMLErrorTemplate.Execute(bs, page)
+	}
+
+	setErrorHeaders(w.Header(), err)
+	if id := de.ID(); id != "" {
+		w.Header().Set("Ory-Error-Id", id)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if h.ContentSecurityPolicy != "" {
+		w.Header().Set("Content-Security-Policy", h.ContentSecurityPolicy)
+	}
+	setDeprecationHeaders(w.Header(), r, h.Reporter)
+	w.WriteHeader(code)
+	_, _ = w.Write(bs.Bytes())
+}
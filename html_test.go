@@ -0,0 +1,83 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLWriter(t *testing.T) {
+	xss := "<script>alert(1)</script>"
+
+	t.Run("case=escapes error fields", func(t *testing.T) {
+		h := NewHTMLWriter(nil)
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("X-Request-ID", "request-id")
+		h.WriteError(rec, r, ErrNotFound().WithReasonf("User %s does not exist", xss).WithID("user_not_found").WithDebug("secret"))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+		assert.Equal(t, DefaultHTMLContentSecurityPolicy, rec.Header().Get("Content-Security-Policy"))
+		assert.Equal(t, "user_not_found", rec.Header().Get("Ory-Error-Id"))
+
+		body := rec.Body.String()
+		assert.NotContains(t, body, xss)
+		assert.Contains(t, body, "User &lt;script&gt;alert(1)&lt;/script&gt; does not exist")
+		assert.Contains(t, body, "The requested resource could not be found")
+		assert.Contains(t, body, "request-id")
+		assert.Contains(t, body, "user_not_found")
+		assert.NotContains(t, body, "secret")
+	})
+
+	t.Run("case=debug", func(t *testing.T) {
+		h := NewHTMLWriter(nil)
+		h.EnableDebug = true
+		rec := httptest.NewRecorder()
+		h.WriteError(rec, httptest.NewRequest("GET", "/", nil), ErrNotFound().WithDebug("secret"))
+
+		assert.Contains(t, rec.Body.String(), "secret")
+	})
+
+	t.Run("case=templates", func(t *testing.T) {
+		h := NewHTMLWriter(nil).
+			WithStatusCodeTemplate(http.StatusNotFound, template.Must(template.New("").Parse(`not found: {{ .Error.Reason }}`))).
+			WithErrorIDTemplate("user_not_found", template.Must(template.New("").Parse(`no user: {{ .Error.Reason }}`)))
+
+		rec := httptest.NewRecorder()
+		h.WriteError(rec, httptest.NewRequest("GET", "/", nil), ErrNotFound().WithReason(xss))
+		assert.Equal(t, "not found: &lt;script&gt;alert(1)&lt;/script&gt;", rec.Body.String())
+
+		rec = httptest.NewRecorder()
+		h.WriteError(rec, httptest.NewRequest("GET", "/", nil), ErrNotFound().WithReason("foo").WithID("user_not_found"))
+		assert.Equal(t, "no user: foo", rec.Body.String())
+
+		rec = httptest.NewRecorder()
+		h.WriteError(rec, httptest.NewRequest("GET", "/", nil), ErrForbidden())
+		assert.Contains(t, rec.Body.String(), "<h1>403 Forbidden</h1>")
+	})
+
+	t.Run("case=write", func(t *testing.T) {
+		h := NewHTMLWriter(nil)
+		rec := httptest.NewRecorder()
+		h.WriteCode(rec, httptest.NewRequest("GET", "/", nil), http.StatusAccepted, xss)
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.NotContains(t, rec.Body.String(), xss)
+	})
+}
+
+func TestTextWriterEscapesHTML(t *testing.T) {
+	h := NewTextWriter(nil, "html")
+	rec := httptest.NewRecorder()
+	h.WriteError(rec, httptest.NewRequest("GET", "/", nil), ErrNotFound().WithError("<script>alert(1)</script>"))
+
+	assert.Equal(t, "&lt;script&gt;alert(1)&lt;/script&gt;", rec.Body.String())
+}
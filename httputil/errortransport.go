@@ -0,0 +1,53 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package httputil
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ErrorTransport is an implementation of http.RoundTripper that turns responses
+// with a status code of 400 or above into errors returned by RoundTrip.
+//
+// Use herodot.ErrorFromResponse as DecodeError to reconstruct herodot errors:
+//
+//	client := &http.Client{Transport: &httputil.ErrorTransport{DecodeError: herodot.ErrorFromResponse}}
+type ErrorTransport struct {
+	// DecodeError converts an error response into an error. If DecodeError
+	// returns nil, the response is passed through. If DecodeError is nil,
+	// a generic error containing the response status is returned.
+	DecodeError func(*http.Response) error
+
+	Base http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *ErrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base().RoundTrip(req)
+	if err != nil || res.StatusCode < 400 {
+		return res, err
+	}
+
+	decode := t.DecodeError
+	if decode == nil {
+		decode = statusError
+	}
+	if err := decode(res); err != nil {
+		_ = res.Body.Close()
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *ErrorTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func statusError(res *http.Response) error {
+	return fmt.Errorf("unexpected response status: %s", res.Status)
+}
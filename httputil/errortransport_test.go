@@ -0,0 +1,53 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package httputil
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/teapot":
+			w.WriteHeader(http.StatusTeapot)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	errTeapot := errors.New("teapot")
+	c := &http.Client{Transport: &ErrorTransport{DecodeError: func(res *http.Response) error {
+		if res.StatusCode == http.StatusTeapot {
+			return errTeapot
+		}
+		return nil
+	}}}
+
+	res, err := c.Get(ts.URL + "/ok")
+	if err != nil || res.StatusCode != http.StatusOK {
+		t.Errorf("expected successful response, got %v", err)
+	}
+
+	_, err = c.Get(ts.URL + "/teapot")
+	if !errors.Is(err, errTeapot) {
+		t.Errorf("expected error %v, got %v", errTeapot, err)
+	}
+
+	res, err = c.Get(ts.URL + "/missing")
+	if err != nil || res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected response to be passed through, got %v", err)
+	}
+
+	c = &http.Client{Transport: new(ErrorTransport)}
+	if _, err = c.Get(ts.URL + "/missing"); err == nil {
+		t.Errorf("expected error for status %d", http.StatusNotFound)
+	}
+}
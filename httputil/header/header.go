@@ -201,6 +201,65 @@ loop:
 	return
 }
 
+// MediaRange describes a media range of an Accept header.
+type MediaRange struct {
+	AcceptSpec
+
+	// Params are the media type parameters preceding the quality. Parameter
+	// names are lower case. Params is nil if there are no parameters.
+	Params map[string]string
+}
+
+// ParseMediaRanges parses Accept headers including the parameters of the
+// media ranges. Parameters following the quality are extensions, which are
+// ignored.
+func ParseMediaRanges(header http.Header, key string) (ranges []MediaRange) {
+loop:
+	for _, s := range header[key] {
+		for {
+			var r MediaRange
+			r.Value, s = expectTokenSlash(s)
+			if r.Value == "" {
+				continue loop
+			}
+			r.Q = 1.0
+			s = skipSpace(s)
+			for weighted := false; strings.HasPrefix(s, ";"); s = skipSpace(s) {
+				var pkey, pvalue string
+				pkey, s = expectToken(skipSpace(s[1:]))
+				if pkey == "" || !strings.HasPrefix(s, "=") {
+					continue loop
+				}
+				pkey = strings.ToLower(pkey)
+				if pkey == "q" && !weighted {
+					r.Q, s = expectQuality(s[1:])
+					if r.Q < 0.0 {
+						continue loop
+					}
+					weighted = true
+					continue
+				}
+				pvalue, s = expectTokenOrQuoted(s[1:])
+				if pvalue == "" {
+					continue loop
+				}
+				if !weighted {
+					if r.Params == nil {
+						r.Params = map[string]string{}
+					}
+					r.Params[pkey] = pvalue
+				}
+			}
+			ranges = append(ranges, r)
+			if !strings.HasPrefix(s, ",") {
+				continue loop
+			}
+			s = skipSpace(s[1:])
+		}
+	}
+	return
+}
+
 func skipSpace(s string) (rest string) {
 	i := 0
 	for ; i < len(s); i++ {
@@ -129,3 +129,25 @@ func TestParseAccept(t *testing.T) {
 		assert.Equal(t, tt.expected, actual)
 	}
 }
+
+var parseMediaRangesTests = []struct {
+	s        string
+	expected []MediaRange
+}{
+	{"text/html; q=0.1, text/plain", []MediaRange{{AcceptSpec: AcceptSpec{"text/html", 0.1}}, {AcceptSpec: AcceptSpec{"text/plain", 1}}}},
+	{"text/html;level=1, text/plain", []MediaRange{{AcceptSpec{"text/html", 1}, map[string]string{"level": "1"}}, {AcceptSpec: AcceptSpec{"text/plain", 1}}}},
+	{"application/json; Version=\"2\"; q=0.5", []MediaRange{{AcceptSpec{"application/json", 0.5}, map[string]string{"version": "2"}}}},
+	{"text/html; q=0.5; ext=foo", []MediaRange{{AcceptSpec: AcceptSpec{"text/html", 0.5}}}},
+
+	// bad cases
+	{"value1; q=0.1.2", []MediaRange{{AcceptSpec: AcceptSpec{"value1", 0.1}}}},
+	{"text/html; level, text/plain", nil},
+}
+
+func TestParseMediaRanges(t *testing.T) {
+	for _, tt := range parseMediaRangesTests {
+		header := http.Header{"Accept": {tt.s}}
+		actual := ParseMediaRanges(header, "Accept")
+		assert.Equal(t, tt.expected, actual, "%s", tt.s)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package httputil
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ory/herodot/httputil/header"
+)
+
+// NegotiateLanguage returns the best offered language for the request's
+// Accept-Language header. Language ranges are tried in order of their quality.
+// For each range, the offers are first searched using lookup as described in
+// RFC 4647, Section 3.4, which falls back from e.g. de-CH to de, and then using
+// basic filtering as described in RFC 4647, Section 3.3.1, which matches e.g.
+// de-CH for de. The wildcard range "*" matches the first offer. Offers matched
+// by a range with a quality of zero are never returned. If no offers match,
+// then defaultOffer is returned.
+func NegotiateLanguage(r *http.Request, offers []string, defaultOffer string) string {
+	specs, excluded := languageRanges(r)
+	for _, spec := range specs {
+		if spec.Value == "*" {
+			for _, offer := range offers {
+				if !excluded(offer) {
+					return offer
+				}
+			}
+			continue
+		}
+
+		// Lookup, e.g. de-CH-1996 -> de-CH -> de.
+		for tag := spec.Value; tag != ""; tag = truncateLanguageTag(tag) {
+			for _, offer := range offers {
+				if strings.EqualFold(tag, offer) && !excluded(offer) {
+					return offer
+				}
+			}
+		}
+
+		// Filtering, e.g. de -> de-CH.
+		for _, offer := range offers {
+			if matchLanguageRange(spec.Value, offer) && !excluded(offer) {
+				return offer
+			}
+		}
+	}
+	return defaultOffer
+}
+
+// FilterLanguages returns the offered languages which match the request's
+// Accept-Language header using basic filtering as described in RFC 4647,
+// Section 3.3.1. The languages are ordered by the quality of the best range
+// matching them. Languages of equal quality keep their order. Offers matched
+// by a range with a quality of zero are removed.
+func FilterLanguages(r *http.Request, offers []string) []string {
+	specs, excluded := languageRanges(r)
+
+	var filtered []string
+	for _, spec := range specs {
+		for _, offer := range offers {
+			if matchLanguageRange(spec.Value, offer) && !excluded(offer) && !containsFold(filtered, offer) {
+				filtered = append(filtered, offer)
+			}
+		}
+	}
+	return filtered
+}
+
+// languageRanges returns the acceptable language ranges ordered by quality, and
+// a function reporting whether a language is excluded by a range of quality zero.
+func languageRanges(r *http.Request) ([]header.AcceptSpec, func(string) bool) {
+	var acceptable, rejected []header.AcceptSpec
+	for _, spec := range header.ParseAccept(r.Header, "Accept-Language") {
+		if spec.Q > 0 {
+			acceptable = append(acceptable, spec)
+		} else if spec.Value != "*" {
+			rejected = append(rejected, spec)
+		}
+	}
+	sort.SliceStable(acceptable, func(i, j int) bool {
+		return acceptable[i].Q > acceptable[j].Q
+	})
+
+	return acceptable, func(language string) bool {
+		for _, spec := range rejected {
+			if matchLanguageRange(spec.Value, language) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// matchLanguageRange reports whether the basic language range matches the language tag.
+func matchLanguageRange(languageRange, tag string) bool {
+	if languageRange == "*" || strings.EqualFold(languageRange, tag) {
+		return true
+	}
+	return len(tag) > len(languageRange) &&
+		strings.EqualFold(tag[:len(languageRange)], languageRange) &&
+		tag[len(languageRange)] == '-'
+}
+
+// truncateLanguageTag removes the last subtag, and a single-character subtag
+// preceding it, from the language tag.
+func truncateLanguageTag(tag string) string {
+	i := strings.LastIndexByte(tag, '-')
+	if i < 0 {
+		return ""
+	}
+	tag = tag[:i]
+	if i := strings.LastIndexByte(tag, '-'); i >= 0 && i == len(tag)-2 {
+		tag = tag[:i]
+	}
+	return tag
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package httputil_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/ory/herodot/httputil"
+)
+
+var negotiateLanguageTests = []struct {
+	s            string
+	offers       []string
+	defaultOffer string
+	expect       string
+}{
+	{"", []string{"en", "de"}, "en", "en"},
+	{"fr", []string{"en", "de"}, "en", "en"},
+	{"fr", []string{"en", "de"}, "", ""},
+	{"de", []string{"en", "de"}, "", "de"},
+	{"DE", []string{"en", "de"}, "", "de"},
+	{"de-CH", []string{"en", "de"}, "", "de"},
+	{"de-CH-1996", []string{"en", "de-CH", "de"}, "", "de-CH"},
+	{"zh-Hant-CN-x-private", []string{"zh-Hant", "zh"}, "", "zh-Hant"},
+	{"en", []string{"de", "en-US"}, "", "en-US"},
+	{"de-CH, en;q=0.9", []string{"en", "de"}, "", "de"},
+	{"fr, de;q=0.5, en;q=0.8", []string{"de", "en"}, "", "en"},
+	{"en;q=0.8, de", []string{"en", "de"}, "", "de"},
+	{"*", []string{"en", "de"}, "", "en"},
+	{"*, en;q=0", []string{"en", "de"}, "", "de"},
+	{"en-GB, en-US;q=0", []string{"en-US"}, "", ""},
+	{"de;q=0", []string{"en", "de"}, "", ""},
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+	for _, tt := range negotiateLanguageTests {
+		r := &http.Request{Header: http.Header{"Accept-Language": {tt.s}}}
+		actual := httputil.NegotiateLanguage(r, tt.offers, tt.defaultOffer)
+		if actual != tt.expect {
+			t.Errorf("NegotiateLanguage(%q, %#v, %q)=%q, want %q", tt.s, tt.offers, tt.defaultOffer, actual, tt.expect)
+		}
+	}
+}
+
+var filterLanguagesTests = []struct {
+	s      string
+	offers []string
+	expect []string
+}{
+	{"", []string{"en", "de"}, nil},
+	{"de", []string{"en", "de-CH", "de-DE", "de"}, []string{"de-CH", "de-DE", "de"}},
+	{"de-CH", []string{"de", "de-CH"}, []string{"de-CH"}},
+	{"en;q=0.5, de", []string{"en-US", "de"}, []string{"de", "en-US"}},
+	{"*, de-CH;q=0", []string{"en", "de-CH", "de"}, []string{"en", "de"}},
+}
+
+func TestFilterLanguages(t *testing.T) {
+	for _, tt := range filterLanguagesTests {
+		r := &http.Request{Header: http.Header{"Accept-Language": {tt.s}}}
+		actual := httputil.FilterLanguages(r, tt.offers)
+		if !reflect.DeepEqual(actual, tt.expect) {
+			t.Errorf("FilterLanguages(%q, %#v)=%#v, want %#v", tt.s, tt.offers, actual, tt.expect)
+		}
+	}
+}
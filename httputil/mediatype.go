@@ -0,0 +1,144 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package httputil
+
+import (
+	"maps"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/ory/herodot/httputil/header"
+)
+
+// MediaTypeMatch is the result of NegotiateMediaType.
+type MediaTypeMatch struct {
+	// Offer is the matched offer as it was passed to NegotiateMediaType.
+	Offer string
+
+	// MediaType is the lower case media type of the offer without parameters,
+	// e.g. application/vnd.ory.v2+json.
+	MediaType string
+
+	// Params are the parameters of the offer, together with the parameters of
+	// the matching media range which the offer does not declare, e.g. the
+	// version of `application/json; version=2` for the offer application/json.
+	Params map[string]string
+
+	// Q is the quality of the matching media range.
+	Q float64
+}
+
+// Specificity of media ranges as described in RFC 9110, Section 12.5.1.
+// Media ranges with parameters declared by the offer are more specific than
+// those without, and those with parameters not declared by the offer are less
+// specific.
+const (
+	matchAny            = iota // */*
+	matchType                  // text/*
+	matchSuffix                // application/json for application/vnd.ory+json
+	matchSuffixWildcard        // application/*+json
+	matchExact                 // application/json
+)
+
+// NegotiateMediaType returns the best offered media type for the request's
+// Accept header. Offers may carry parameters, e.g. `application/json; version=2`.
+//
+// Each offer gets the quality of the most specific media range matching it as
+// described in RFC 9110, Section 12.5.1. Media ranges match offers by their type
+// and subtype, where "*" matches any type or subtype, and by the structured
+// syntax suffix, so that application/json and application/*+json match e.g.
+// application/vnd.ory.v2+json. The parameters of a media range must have the same
+// values as the parameters the offer declares, while parameters the offer does not
+// declare are returned as part of the match.
+//
+// The offer with the highest quality wins. If two offers match with equal quality,
+// the offer matched by the more specific media range is preferred, and then the
+// offer earlier in the list. If the request has no Accept header, the first offer is
+// returned. If no offer is acceptable, false is returned.
+func NegotiateMediaType(r *http.Request, offers []string) (MediaTypeMatch, bool) {
+	specs := header.ParseMediaRanges(r.Header, "Accept")
+	noAccept := len(r.Header.Values("Accept")) == 0
+
+	var best MediaTypeMatch
+	bestSpecificity := -1
+	found := false
+	for _, offer := range offers {
+		mediaType, params, err := mime.ParseMediaType(offer)
+		if err != nil {
+			continue
+		}
+		if noAccept {
+			return MediaTypeMatch{Offer: offer, MediaType: mediaType, Params: params, Q: 1}, true
+		}
+
+		var spec *header.MediaRange
+		specificity := -1
+		for i := range specs {
+			if s := matchMediaRange(&specs[i], mediaType, params); s > specificity {
+				spec, specificity = &specs[i], s
+			}
+		}
+		if spec == nil || spec.Q == 0 {
+			continue
+		}
+		if found && (spec.Q < best.Q || (spec.Q == best.Q && specificity <= bestSpecificity)) {
+			continue
+		}
+
+		merged := maps.Clone(params)
+		for k, v := range spec.Params {
+			if _, ok := merged[k]; !ok {
+				merged[k] = v
+			}
+		}
+		best = MediaTypeMatch{Offer: offer, MediaType: mediaType, Params: merged, Q: spec.Q}
+		bestSpecificity = specificity
+		found = true
+	}
+	return best, found
+}
+
+// matchMediaRange returns the specificity with which the media range matches the
+// media type, or -1 if it does not match.
+func matchMediaRange(spec *header.MediaRange, mediaType string, params map[string]string) int {
+	matched, unmatched := 0, 0
+	for k, v := range spec.Params {
+		pv, ok := params[k]
+		switch {
+		case !ok:
+			unmatched++
+		case !strings.EqualFold(pv, v):
+			return -1
+		default:
+			matched++
+		}
+	}
+
+	rangeType, rangeSubtype, ok := strings.Cut(strings.ToLower(spec.Value), "/")
+	if !ok {
+		return -1
+	}
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	_, suffix, hasSuffix := strings.Cut(subtype, "+")
+
+	var specificity int
+	switch {
+	case rangeType == "*" && rangeSubtype == "*":
+		specificity = matchAny
+	case rangeType != typ:
+		return -1
+	case rangeSubtype == subtype:
+		specificity = matchExact
+	case rangeSubtype == "*":
+		specificity = matchType
+	case hasSuffix && rangeSubtype == "*+"+suffix:
+		specificity = matchSuffixWildcard
+	case hasSuffix && rangeSubtype == suffix:
+		specificity = matchSuffix
+	default:
+		return -1
+	}
+	return specificity*100 + matched*10 - unmatched
+}
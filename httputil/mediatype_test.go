@@ -0,0 +1,56 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package httputil_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/ory/herodot/httputil"
+)
+
+var negotiateMediaTypeTests = []struct {
+	s      string
+	offers []string
+	expect string
+	params map[string]string
+}{
+	{"", []string{"application/json", "text/html"}, "application/json", map[string]string{}},
+	{"text/html", []string{"application/json", "text/html"}, "text/html", map[string]string{}},
+	{"image/png", []string{"application/json", "text/html"}, "", nil},
+	{"text/*, text/html;q=0", []string{"text/html", "text/plain"}, "text/plain", map[string]string{}},
+	{"text/*;q=0.5, */*;q=0.1", []string{"application/json", "text/html"}, "text/html", map[string]string{}},
+	{"*/*", []string{"application/json", "text/html"}, "application/json", map[string]string{}},
+
+	// structured syntax suffixes
+	{"application/vnd.ory.v2+json", []string{"application/vnd.ory.v1+json", "application/vnd.ory.v2+json"}, "application/vnd.ory.v2+json", map[string]string{}},
+	{"application/json", []string{"text/html", "application/vnd.ory.v2+json"}, "application/vnd.ory.v2+json", map[string]string{}},
+	{"application/*+json", []string{"application/xml", "application/problem+json"}, "application/problem+json", map[string]string{}},
+	{"application/json", []string{"application/problem+json", "application/json"}, "application/json", map[string]string{}},
+	{"application/json;q=0.5, application/*+json", []string{"application/json", "application/problem+json"}, "application/problem+json", map[string]string{}},
+
+	// parameters
+	{"application/json; version=2", []string{"application/json; version=1", "application/json; version=2"}, "application/json; version=2", map[string]string{"version": "2"}},
+	{"application/json; version=3", []string{"application/json; version=1", "application/json; version=2"}, "", nil},
+	{"application/json; version=2", []string{"application/json"}, "application/json", map[string]string{"version": "2"}},
+	{"text/html; charset=UTF-8", []string{"text/html; charset=utf-8"}, "text/html; charset=utf-8", map[string]string{"charset": "utf-8"}},
+	{"text/plain, text/plain; format=flowed; q=0", []string{"text/plain; format=flowed", "text/plain"}, "text/plain", map[string]string{}},
+}
+
+func TestNegotiateMediaType(t *testing.T) {
+	for _, tt := range negotiateMediaTypeTests {
+		r := &http.Request{Header: http.Header{}}
+		if tt.s != "" {
+			r.Header.Set("Accept", tt.s)
+		}
+		actual, ok := httputil.NegotiateMediaType(r, tt.offers)
+		if ok != (tt.expect != "") || actual.Offer != tt.expect {
+			t.Errorf("NegotiateMediaType(%q, %#v)=%q, %t, want %q", tt.s, tt.offers, actual.Offer, ok, tt.expect)
+		}
+		if ok && !reflect.DeepEqual(actual.Params, tt.params) {
+			t.Errorf("NegotiateMediaType(%q, %#v) params=%#v, want %#v", tt.s, tt.offers, actual.Params, tt.params)
+		}
+	}
+}
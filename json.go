@@ -7,7 +7,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	stderr "errors"
 	"net/http"
 
 	"github.com/pkg/errors"
@@ -21,6 +20,24 @@ func (e *ErrorContainer) ID() string {
 	return e.Error.ID()
 }
 
+// UnmarshalJSON implements json.Unmarshaler. Besides the `{"error":{...}}` envelope,
+// it also accepts a bare DefaultError.
+func (e *ErrorContainer) UnmarshalJSON(b []byte) error {
+	var envelope struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(b, &envelope); err != nil {
+		return err
+	}
+	if e.Error == nil {
+		e.Error = new(DefaultError)
+	}
+	if len(envelope.Error) > 0 {
+		return json.Unmarshal(envelope.Error, e.Error)
+	}
+	return json.Unmarshal(b, e.Error)
+}
+
 type ErrorReporter interface {
 	ReportError(r *http.Request, code int, err error, args ...interface{})
 }
@@ -37,6 +54,21 @@ type JSONWriter struct {
 	Reporter      ErrorReporter
 	ErrorEnhancer func(r *http.Request, err error) interface{}
 	EnableDebug   bool
+
+	// DebugPolicy, if set, enables debug output for individual requests
+	// even if EnableDebug is false.
+	DebugPolicy DebugPolicy
+
+	// ScrubPolicy, if set, scrubs errors before they are written.
+	ScrubPolicy *ScrubPolicy
+
+	// DebugTrace configures the stack trace and causes added to errors
+	// if debug output is enabled.
+	DebugTrace DebugTraceOptions
+
+	// Catalog, if set, translates error messages into the language
+	// negotiated from the Accept-Language header.
+	Catalog Catalog
 }
 
 var _ Writer = (*JSONWriter)(nil)
@@ -123,6 +155,7 @@ func (h *JSONWriter) WriteCode(w http.ResponseWriter, r *http.Request, code int,
 	}
 
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	setDeprecationHeaders(w.Header(), r, h.Reporter)
 	w.WriteHeader(code)
 	_, _ = w.Write(bs.Bytes())
 }
@@ -138,11 +171,7 @@ func (h *JSONWriter) WriteCreated(w http.ResponseWriter, r *http.Request, locati
 // asserting statusCodeCarrier. If the error does not implement statusCodeCarrier, the status code
 // is set to 500.
 func (h *JSONWriter) WriteError(w http.ResponseWriter, r *http.Request, err error, opts ...Option) {
-	if c := StatusCodeCarrier(nil); stderr.As(err, &c) {
-		h.WriteErrorCode(w, r, c.StatusCode(), err)
-	} else {
-		h.WriteErrorCode(w, r, http.StatusInternalServerError, err, opts...)
-	}
+	h.WriteErrorCode(w, r, statusCodeOf(err), err, opts...)
 }
 
 // WriteErrorCode writes an error to ResponseWriter and forces an error code.
@@ -157,11 +186,14 @@ func (h *JSONWriter) WriteErrorCode(w http.ResponseWriter, r *http.Request, code
 		code = StatusClientClosedRequest
 	}
 
-	if !o.noLog {
+	if !o.noLog && !isNoLogError(err) {
 		// All errors land here, so it's a really good idea to do the logging here as well!
 		h.Reporter.ReportError(r, code, coalesceError(err), "An error occurred while handling a request")
 	}
+	err = localizeResponse(w, r, h.Catalog, err)
+	debug := debugEnabled(r, h.EnableDebug, h.DebugPolicy)
 
+	setErrorHeaders(w.Header(), err)
 	w.Header().Set("Content-Type", "application/json")
 
 	// Enhancing must happen after logging or context will be lost.
@@ -172,19 +204,27 @@ func (h *JSONWriter) WriteErrorCode(w http.ResponseWriter, r *http.Request, code
 	if id, ok := payload.(interface{ ID() string }); ok {
 		w.Header().Set("Ory-Error-Id", id.ID())
 	}
-	if de, ok := payload.(*DefaultError); ok && !h.EnableDebug {
-		de2 := de.Clone()
-		de2.DebugField = ""
-		payload = de2
+	if de, ok := payload.(*DefaultError); ok {
+		de = debugError(de, debug, h.DebugTrace)
+		de.WarningsField = withDeprecationWarning(r, de.WarningsField)
+		payload = h.ScrubPolicy.Scrub(de, debug)
+	}
+	if ac, ok := payload.(*AIP193ErrorContainer); ok {
+		ac2 := *ac
+		ac2.Debug = debug
+		ac2.Code = code
+		ac2.Error = h.ScrubPolicy.Scrub(ac.Error, debug)
+		payload = &ac2
 	}
-	if ec, ok := payload.(*ErrorContainer); ok && !h.EnableDebug {
-		de2 := ec.Error.Clone()
-		de2.DebugField = ""
+	if ec, ok := payload.(*ErrorContainer); ok {
 		ec2 := *ec
-		ec2.Error = de2
+		ec2.Error = debugError(ec.Error, debug, h.DebugTrace)
+		ec2.Error.WarningsField = withDeprecationWarning(r, ec2.Error.WarningsField)
+		ec2.Error = h.ScrubPolicy.Scrub(ec2.Error, debug)
 		payload = ec2
 	}
 
+	setDeprecationHeaders(w.Header(), r, h.Reporter)
 	w.WriteHeader(code)
 
 	if err := json.NewEncoder(w).Encode(payload); err != nil {
@@ -0,0 +1,51 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"net/http"
+
+	"github.com/ory/herodot/httputil"
+)
+
+// localizeError translates the message of err into language using the catalog. If err has no
+// message ID or the catalog has no translation, it returns false. Otherwise, it returns a
+// *DefaultError with the translated message, which also carries it as its LocalizedMessage.
+func localizeError(c Catalog, language string, err error) (*DefaultError, bool) {
+	if c == nil || language == "" {
+		return nil, false
+	}
+	id, args := messageOf(err)
+	if id == "" {
+		return nil, false
+	}
+
+	message, ok := c.Translate(language, id, args)
+	if !ok {
+		return nil, false
+	}
+
+	de := ToDefaultError(err, "")
+	de.ErrorField = message
+	de.LocalizedMessageField = &LocalizedMessage{Locale: language, Message: message}
+	de.localized = true
+	return de, true
+}
+
+// localizeResponse translates the message of err into the language preferred by the request
+// and sets the Content-Language and Vary headers accordingly. Errors which were already
+// localized, e.g. by a NegotiationHandler, are returned as they are.
+func localizeResponse(w http.ResponseWriter, r *http.Request, c Catalog, err error) error {
+	if de, ok := err.(*DefaultError); c == nil || ok && de.localized {
+		return err
+	}
+
+	addVary(w.Header(), "Accept-Language")
+	language := httputil.NegotiateLanguage(r, c.Languages(), "")
+	if de, ok := localizeError(c, language, err); ok {
+		w.Header().Set("Content-Language", language)
+		return de
+	}
+	return err
+}
@@ -0,0 +1,195 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ory/herodot/internal"
+)
+
+func testCatalog(t *testing.T) *MessageCatalog {
+	c, err := LoadMessageCatalog(fstest.MapFS{
+		"de.json":    {Data: []byte(`{"herodot.not_found": "Die angeforderte Ressource wurde nicht gefunden", "user_not_found": "Benutzer {{.user}} existiert nicht"}`)},
+		"pt-BR.yaml": {Data: []byte("herodot.not_found: O recurso solicitado não foi encontrado\n")},
+		"README.md":  {Data: []byte("ignored")},
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func TestMessageCatalog(t *testing.T) {
+	c := testCatalog(t)
+	assert.Equal(t, []string{"de", "pt-BR"}, c.Languages())
+
+	msg, ok := c.Translate("de", "user_not_found", map[string]interface{}{"user": "1234"})
+	require.True(t, ok)
+	assert.Equal(t, "Benutzer 1234 existiert nicht", msg)
+
+	_, ok = c.Translate("pt-br", "herodot.not_found", nil)
+	assert.True(t, ok)
+	_, ok = c.Translate("fr", "herodot.not_found", nil)
+	assert.False(t, ok)
+
+	_, err := LoadMessageCatalog(fstest.MapFS{"de.json": {Data: []byte(`{"foo": "{{.foo"}`)}})
+	assert.Error(t, err)
+}
+
+func TestLocalizedWriters(t *testing.T) {
+	c := testCatalog(t)
+	for k, tc := range []struct {
+		err              error
+		acceptLanguage   string
+		expectedMessage  string
+		expectedLanguage string
+	}{
+		{
+			err:              errors.WithStack(ErrNotFound()),
+			acceptLanguage:   "de-CH, de;q=0.9",
+			expectedMessage:  "Die angeforderte Ressource wurde nicht gefunden",
+			expectedLanguage: "de",
+		},
+		{
+			err:              ErrNotFound().WithMessageID("user_not_found").WithMessageArg("user", "1234"),
+			acceptLanguage:   "de",
+			expectedMessage:  "Benutzer 1234 existiert nicht",
+			expectedLanguage: "de",
+		},
+		{
+			err:             ErrNotFound().WithError("User 1234 does not exist"),
+			acceptLanguage:  "de",
+			expectedMessage: "User 1234 does not exist",
+		},
+		{
+			err:             ErrNotFound(),
+			acceptLanguage:  "fr",
+			expectedMessage: "The requested resource could not be found",
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			jw := NewJSONWriter(nil)
+			jw.Catalog = c
+			tw := NewTextWriter(nil, "plain")
+			tw.Catalog = c
+
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("Accept-Language", tc.acceptLanguage)
+
+			rec := httptest.NewRecorder()
+			jw.WriteError(rec, r, tc.err)
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.Equal(t, tc.expectedLanguage, rec.Header().Get("Content-Language"))
+			assert.Equal(t, "Accept-Language", rec.Header().Get("Vary"))
+
+			var ec ErrorContainer
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&ec))
+			assert.Equal(t, tc.expectedMessage, ec.Error.Error())
+			if tc.expectedLanguage != "" {
+				assert.Equal(t, &LocalizedMessage{Locale: tc.expectedLanguage, Message: tc.expectedMessage}, ec.Error.LocalizedMessage())
+			}
+
+			rec = httptest.NewRecorder()
+			tw.WriteError(rec, r, tc.err)
+			assert.Equal(t, tc.expectedMessage, rec.Body.String())
+		})
+	}
+}
+
+func TestLocalizationInterceptor(t *testing.T) {
+	server := &erroringGreeter{err: errors.WithStack(ErrNotFound())}
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(UnaryLocalizationInterceptor(testCatalog(t)), UnaryErrorUnwrapInterceptor))
+	internal.RegisterGreeterServer(s, server)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	serveErr := &errgroup.Group{}
+	serveErr.Go(func() error {
+		return s.Serve(l)
+	})
+	t.Cleanup(func() {
+		s.Stop()
+		require.NoError(t, serveErr.Wait())
+	})
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "accept-language", "pt-BR")
+	var header metadata.MD
+	_, err = internal.NewGreeterClient(conn).SayHello(ctx, &internal.HelloRequest{}, grpc.Header(&header))
+	require.Error(t, err)
+
+	st := status.Convert(err)
+	assert.Equal(t, "O recurso solicitado não foi encontrado", st.Message())
+	assert.Equal(t, []string{"pt-BR"}, header.Get("content-language"))
+
+	var lm *errdetails.LocalizedMessage
+	for _, d := range st.Details() {
+		if d, ok := d.(*errdetails.LocalizedMessage); ok {
+			lm = d
+		}
+	}
+	require.NotNil(t, lm)
+	assert.Equal(t, "pt-BR", lm.Locale)
+	assert.Equal(t, "O recurso solicitado não foi encontrado", lm.Message)
+}
+
+func TestLocalizedHTMLWriter(t *testing.T) {
+	h := NewHTMLWriter(nil)
+	h.Catalog = testCatalog(t)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Language", "de-AT")
+	rec := httptest.NewRecorder()
+	h.WriteError(rec, r, ErrNotFound())
+
+	assert.Equal(t, "de", rec.Header().Get("Content-Language"))
+	assert.Contains(t, rec.Body.String(), `<html lang="de">`)
+	assert.Contains(t, rec.Body.String(), "Die angeforderte Ressource wurde nicht gefunden")
+
+	rec = httptest.NewRecorder()
+	h.WriteError(rec, httptest.NewRequest("GET", "/", nil), ErrNotFound())
+	assert.Contains(t, rec.Body.String(), `<html lang="en">`)
+}
+
+func TestLocalizedNegotiationHandler(t *testing.T) {
+	inner, err := LoadMessageCatalog(fstest.MapFS{
+		"de.json": {Data: []byte(`{"herodot.not_found": "Nicht gefunden"}`)},
+	})
+	require.NoError(t, err)
+
+	jw := NewJSONWriter(nil)
+	jw.Catalog = inner
+	h := new(NegotiationHandler).Register("application/json", jw)
+	h.Catalog = testCatalog(t)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Language", "de")
+	rec := httptest.NewRecorder()
+	h.WriteError(rec, r, ErrNotFound())
+
+	assert.Equal(t, "de", rec.Header().Get("Content-Language"))
+	assert.Equal(t, []string{"Accept", "Accept-Language"}, rec.Header().Values("Vary"))
+	var ec ErrorContainer
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&ec))
+	assert.Equal(t, "Die angeforderte Ressource wurde nicht gefunden", ec.Error.Error(), "the message must only be localized by the handler")
+}
@@ -5,119 +5,176 @@ package herodot
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/ory/herodot/httputil"
 )
 
 // NegotiationHandler automatically negotiates the content type with the request client.
+//
+// Media types are registered against arbitrary Writer implementations. Errors are
+// negotiated against the media types registered with RegisterError, or against the
+// media types registered with Register if no error media types were registered.
+// The first registered media type is used if the client does not send an Accept
+// header or, unless Strict is set, if none of the registered media types are acceptable.
 type NegotiationHandler struct {
-	json  *JSONWriter
-	plain *TextWriter
-	html  *TextWriter
-	types []string
+	// Strict, if set, answers requests for which none of the registered media types
+	// are acceptable with 406 Not Acceptable. Errors are always written, using the
+	// first registered error media type if none is acceptable.
+	Strict bool
+
+	// Catalog, if set, translates error messages into the language negotiated from
+	// the Accept-Language header before they are passed to the registered writers,
+	// which then do not translate them again.
+	Catalog Catalog
+
+	offers       []string
+	writers      map[string]Writer
+	errorOffers  []string
+	errorWriters map[string]Writer
+	reporter     ErrorReporter
 }
 
-// NewNegotiationHandler creates a new NewNegotiationHandler.
+var _ Writer = (*NegotiationHandler)(nil)
+
+// NewNegotiationHandler creates a new NewNegotiationHandler which writes JSON, plain text and HTML
+// responses, and additionally RFC 9457 problem details for errors.
 func NewNegotiationHandler(reporter ErrorReporter) *NegotiationHandler {
-	return &NegotiationHandler{
-		json:  NewJSONWriter(reporter),
-		plain: NewTextWriter(reporter, "plain"),
-		html:  NewTextWriter(reporter, "html"),
-		types: []string{
-			"application/json",
-		},
+	json := NewJSONWriter(reporter)
+	plain := NewTextWriter(reporter, "plain")
+	html := NewHTMLWriter(reporter)
+
+	return (&NegotiationHandler{reporter: reporter}).
+		Register("application/json", json).
+		Register("text/plain", plain).
+		Register("text/html", html).
+		RegisterError("application/json", json).
+		RegisterError("application/problem+json", NewProblemWriter(reporter)).
+		RegisterError("text/plain", plain).
+		RegisterError("text/html", html)
+}
+
+// Register registers a writer for the given media type. Registering a media type
+// twice replaces the previously registered writer. Mutates and returns the receiver.
+func (h *NegotiationHandler) Register(mediaType string, w Writer) *NegotiationHandler {
+	h.offers, h.writers = register(h.offers, h.writers, mediaType, w)
+	return h
+}
+
+// RegisterError registers a writer for errors of the given media type. Registering
+// a media type twice replaces the previously registered writer. Mutates and returns the receiver.
+func (h *NegotiationHandler) RegisterError(mediaType string, w Writer) *NegotiationHandler {
+	h.errorOffers, h.errorWriters = register(h.errorOffers, h.errorWriters, mediaType, w)
+	return h
+}
+
+func register(offers []string, writers map[string]Writer, mediaType string, w Writer) ([]string, map[string]Writer) {
+	mediaType = strings.ToLower(mediaType)
+	if writers == nil {
+		writers = map[string]Writer{}
+	}
+	if _, ok := writers[mediaType]; !ok {
+		offers = append(offers, mediaType)
+	}
+	writers[mediaType] = w
+	return offers, writers
+}
+
+// MediaTypes returns the registered media types in order of registration.
+func (h *NegotiationHandler) MediaTypes() []string {
+	return append([]string(nil), h.offers...)
+}
+
+// negotiate returns the writer for the best matching media type. If no media type
+// is acceptable, the writer of the first offer is returned together with false.
+func negotiate(r *http.Request, offers []string, writers map[string]Writer) (Writer, bool) {
+	if len(offers) == 0 {
+		return nil, false
+	}
+	if match, ok := httputil.NegotiateMediaType(r, offers); ok {
+		return writers[match.Offer], true
+	}
+	return writers[offers[0]], false
+}
+
+func (h *NegotiationHandler) writer(w http.ResponseWriter, r *http.Request) Writer {
+	addVary(w.Header(), "Accept")
+
+	writer, ok := negotiate(r, h.offers, h.writers)
+	if writer == nil {
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		return nil
 	}
+	if !ok && h.Strict {
+		h.WriteError(w, r, ErrNotAcceptable().
+			WithReasonf("The request can only be answered with one of the following content types: %s", strings.Join(h.offers, ", ")).
+			WithDetail("available_types", h.MediaTypes()))
+		return nil
+	}
+	return writer
+}
+
+func (h *NegotiationHandler) errorWriter(w http.ResponseWriter, r *http.Request) Writer {
+	addVary(w.Header(), "Accept")
+
+	offers, writers := h.errorOffers, h.errorWriters
+	if len(offers) == 0 {
+		offers, writers = h.offers, h.writers
+	}
+	writer, _ := negotiate(r, offers, writers)
+	if writer == nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+	return writer
 }
 
 // Write a response object to the ResponseWriter with status code 200.
-func (h *NegotiationHandler) Write(w http.ResponseWriter, r *http.Request, e interface{}) {
-	switch httputil.NegotiateContentType(r, []string{}, "application/json") {
-	case "text/html":
-		h.html.Write(w, r, e)
-		return
-	case "text/plain":
-		h.plain.Write(w, r, e)
-		return
-	case "application/json":
-		h.json.Write(w, r, e)
-		return
-	default:
-		h.json.Write(w, r, e)
-		return
+func (h *NegotiationHandler) Write(w http.ResponseWriter, r *http.Request, e interface{}, opts ...EncoderOptions) {
+	if writer := h.writer(w, r); writer != nil {
+		writer.Write(w, r, e, opts...)
 	}
 }
 
 // WriteCode writes a response object to the ResponseWriter and sets a response code.
-func (h *NegotiationHandler) WriteCode(w http.ResponseWriter, r *http.Request, code int, e interface{}) {
-	switch httputil.NegotiateContentType(r, []string{}, "application/json") {
-	case "text/html":
-		h.html.WriteCode(w, r, code, e)
-		return
-	case "text/plain":
-		h.plain.WriteCode(w, r, code, e)
-		return
-	case "application/json":
-		h.json.WriteCode(w, r, code, e)
-		return
-	default:
-		h.json.WriteCode(w, r, code, e)
-		return
+func (h *NegotiationHandler) WriteCode(w http.ResponseWriter, r *http.Request, code int, e interface{}, opts ...EncoderOptions) {
+	if writer := h.writer(w, r); writer != nil {
+		writer.WriteCode(w, r, code, e, opts...)
 	}
 }
 
 // WriteCreated writes a response object to the ResponseWriter with status code 201 and
 // the Location header set to location.
 func (h *NegotiationHandler) WriteCreated(w http.ResponseWriter, r *http.Request, location string, e interface{}) {
-	switch httputil.NegotiateContentType(r, []string{}, "application/json") {
-	case "text/html":
-		h.html.WriteCreated(w, r, location, e)
-		return
-	case "text/plain":
-		h.plain.WriteCreated(w, r, location, e)
-		return
-	case "application/json":
-		h.json.WriteCreated(w, r, location, e)
-		return
-	default:
-		h.json.WriteCreated(w, r, location, e)
-		return
+	if writer := h.writer(w, r); writer != nil {
+		writer.WriteCreated(w, r, location, e)
 	}
 }
 
 // WriteError writes an error to ResponseWriter and tries to extract the error's status code by
 // asserting statusCodeCarrier. If the error does not implement statusCodeCarrier, the status code
 // is set to 500.
-func (h *NegotiationHandler) WriteError(w http.ResponseWriter, r *http.Request, err error) {
-	switch httputil.NegotiateContentType(r, []string{}, "application/json") {
-	case "text/html":
-		h.html.WriteError(w, r, err)
-		return
-	case "text/plain":
-		h.plain.WriteError(w, r, err)
-		return
-	case "application/json":
-		h.json.WriteError(w, r, err)
-		return
-	default:
-		h.json.WriteError(w, r, err)
-		return
+func (h *NegotiationHandler) WriteError(w http.ResponseWriter, r *http.Request, err error, opts ...Option) {
+	if writer := h.errorWriter(w, r); writer != nil {
+		writer.WriteError(w, r, localizeResponse(w, r, h.Catalog, err), opts...)
 	}
 }
 
 // WriteErrorCode writes an error to ResponseWriter and forces an error code.
-func (h *NegotiationHandler) WriteErrorCode(w http.ResponseWriter, r *http.Request, code int, err error) {
-	switch httputil.NegotiateContentType(r, []string{}, "application/json") {
-	case "text/html":
-		h.html.WriteErrorCode(w, r, code, err)
-		return
-	case "text/plain":
-		h.plain.WriteErrorCode(w, r, code, err)
-		return
-	case "application/json":
-		h.json.WriteErrorCode(w, r, code, err)
-		return
-	default:
-		h.json.WriteErrorCode(w, r, code, err)
-		return
+func (h *NegotiationHandler) WriteErrorCode(w http.ResponseWriter, r *http.Request, code int, err error, opts ...Option) {
+	if writer := h.errorWriter(w, r); writer != nil {
+		writer.WriteErrorCode(w, r, code, localizeResponse(w, r, h.Catalog, err), opts...)
+	}
+}
+
+// addVary adds value to the Vary header unless it is already present.
+func addVary(header http.Header, value string) {
+	for _, v := range header.Values("Vary") {
+		for _, field := range strings.Split(v, ",") {
+			field = strings.TrimSpace(field)
+			if field == "*" || strings.EqualFold(field, value) {
+				return
+			}
+		}
 	}
+	header.Add("Vary", value)
 }
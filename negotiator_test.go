@@ -0,0 +1,120 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiationHandler(t *testing.T) {
+	for k, tc := range []struct {
+		accept              string
+		strict              bool
+		expectedCode        int
+		expectedContentType string
+	}{
+		{accept: "", expectedCode: http.StatusOK, expectedContentType: "application/json; charset=utf-8"},
+		{accept: "*/*", expectedCode: http.StatusOK, expectedContentType: "application/json; charset=utf-8"},
+		{accept: "text/html", expectedCode: http.StatusOK, expectedContentType: "text/html; charset=utf-8"},
+		{accept: "text/plain, application/json;q=0.5", expectedCode: http.StatusOK, expectedContentType: "text/plain"},
+		{accept: "text/*;q=0.5, application/json", expectedCode: http.StatusOK, expectedContentType: "application/json; charset=utf-8"},
+		{accept: "image/png", expectedCode: http.StatusOK, expectedContentType: "application/json; charset=utf-8"},
+		{accept: "image/png", strict: true, expectedCode: http.StatusNotAcceptable, expectedContentType: "application/json"},
+	} {
+		t.Run(fmt.Sprintf("case=%d/accept=%s", k, tc.accept), func(t *testing.T) {
+			h := NewNegotiationHandler(nil)
+			h.Strict = tc.strict
+
+			rec := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/", nil)
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+			h.Write(rec, r, "foo")
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, tc.expectedContentType, rec.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+		})
+	}
+
+	t.Run("case=strict lists available types", func(t *testing.T) {
+		h := NewNegotiationHandler(nil)
+		h.Strict = true
+
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", "image/png")
+		h.Write(rec, r, "foo")
+
+		var e ErrorContainer
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&e))
+		assert.Equal(t, http.StatusNotAcceptable, e.Error.StatusCode())
+		assert.Equal(t, []interface{}{"application/json", "text/plain", "text/html"}, e.Error.Details()["available_types"])
+	})
+
+	t.Run("case=errors are negotiated separately", func(t *testing.T) {
+		h := NewNegotiationHandler(nil)
+
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", "application/problem+json")
+		h.WriteError(rec, r, ErrNotFound())
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("case=custom writer", func(t *testing.T) {
+		h := new(NegotiationHandler).
+			Register("application/json", NewJSONWriter(nil)).
+			Register("text/csv", NewTextWriter(nil, "csv"))
+
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", "text/csv")
+		h.Write(rec, r, "a,b")
+		assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "a,b", rec.Body.String())
+
+		rec = httptest.NewRecorder()
+		h.WriteError(rec, r, ErrNotFound())
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+	})
+}
+
+func TestNegotiationHandlerMediaTypeParameters(t *testing.T) {
+	v1, v2 := NewTextWriter(nil, "plain"), NewJSONWriter(nil)
+	h := new(NegotiationHandler).
+		Register("application/vnd.ory.v1+json", v1).
+		Register("application/vnd.ory.v2+json", v2).
+		Register("text/plain; version=1", v1).
+		Register("text/plain; version=2", v2)
+
+	for _, tc := range []struct {
+		accept, expectedContentType string
+	}{
+		{accept: "application/vnd.ory.v2+json", expectedContentType: "application/json; charset=utf-8"},
+		{accept: "application/vnd.ory.v1+json", expectedContentType: "text/plain"},
+		{accept: "application/json", expectedContentType: "text/plain"},
+		{accept: "text/plain; version=2", expectedContentType: "application/json; charset=utf-8"},
+		{accept: "text/plain; version=1, text/plain; version=2; q=0.5", expectedContentType: "text/plain"},
+	} {
+		t.Run("accept="+tc.accept, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("Accept", tc.accept)
+			h.Write(rec, r, "foo")
+			assert.Equal(t, tc.expectedContentType, rec.Header().Get("Content-Type"))
+		})
+	}
+}
@@ -3,6 +3,10 @@
 
 package herodot
 
+import (
+	stderr "errors"
+)
+
 func NoLog() Option {
 	return func(o *options) {
 		o.noLog = true
@@ -22,3 +26,26 @@ type Option func(*options)
 type options struct {
 	noLog bool
 }
+
+type noLogError struct {
+	error
+}
+
+func (e *noLogError) Unwrap() error {
+	return e.error
+}
+
+// NoLogError marks err as not to be reported, like NoLog does for a single write. It is
+// honored by the writers and the gRPC reporting interceptors, which have no options.
+func NoLogError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &noLogError{error: err}
+}
+
+// isNoLogError returns true if err was marked using NoLogError.
+func isNoLogError(err error) bool {
+	var e *noLogError
+	return stderr.As(err, &e)
+}
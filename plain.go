@@ -6,6 +6,8 @@ package herodot
 import (
 	"context"
 	"fmt"
+	"html"
+	"io"
 	"net/http"
 
 	"github.com/pkg/errors"
@@ -14,7 +16,19 @@ import (
 // TextWriter outputs plain text
 type TextWriter struct {
 	Reporter    ErrorReporter
+	EnableDebug bool
 	contentType string
+
+	// DebugPolicy, if set, enables debug output for individual requests
+	// even if EnableDebug is false.
+	DebugPolicy DebugPolicy
+
+	// ScrubPolicy, if set, scrubs errors before they are written.
+	ScrubPolicy *ScrubPolicy
+
+	// Catalog, if set, translates error messages into the language
+	// negotiated from the Accept-Language header.
+	Catalog Catalog
 }
 
 var _ Writer = (*TextWriter)(nil)
@@ -29,6 +43,9 @@ func NewTextWriter(reporter ErrorReporter, contentType string) *TextWriter {
 		Reporter:    reporter,
 		contentType: "text/" + contentType,
 	}
+	if writer.Reporter == nil {
+		writer.Reporter = &stdReporter{}
+	}
 
 	return writer
 }
@@ -49,8 +66,9 @@ func (h *TextWriter) WriteCode(w http.ResponseWriter, r *http.Request, code int,
 	}
 
 	w.Header().Set("Content-Type", h.contentType)
+	setDeprecationHeaders(w.Header(), r, h.Reporter)
 	w.WriteHeader(code)
-	fmt.Fprintf(w, "%s", e)
+	h.print(w, "%s", e)
 }
 
 // WriteCreated writes a response object to the ResponseWriter with status code 201 and
@@ -63,17 +81,13 @@ func (h *TextWriter) WriteCreated(w http.ResponseWriter, r *http.Request, locati
 // WriteError writes an error to ResponseWriter and tries to extract the error's status code by
 // asserting statusCodeCarrier. If the error does not implement statusCodeCarrier, the status code
 // is set to 500.
-func (h *TextWriter) WriteError(w http.ResponseWriter, r *http.Request, err error, _ ...Option) {
-	if s, ok := errors.Cause(coalesceError(err)).(StatusCodeCarrier); ok {
-		h.WriteErrorCode(w, r, s.StatusCode(), err)
-		return
-	} else {
-		h.WriteErrorCode(w, r, http.StatusInternalServerError, err)
-	}
+func (h *TextWriter) WriteError(w http.ResponseWriter, r *http.Request, err error, opts ...Option) {
+	h.WriteErrorCode(w, r, statusCodeOf(err), err, opts...)
 }
 
 // WriteErrorCode writes an error to ResponseWriter and forces an error code.
-func (h *TextWriter) WriteErrorCode(w http.ResponseWriter, r *http.Request, code int, err error, _ ...Option) {
+func (h *TextWriter) WriteErrorCode(w http.ResponseWriter, r *http.Request, code int, err error, opts ...Option) {
+	o := newOptions(opts)
 	err = coalesceError(err)
 
 	if code == 0 {
@@ -84,13 +98,46 @@ func (h *TextWriter) WriteErrorCode(w http.ResponseWriter, r *http.Request, code
 		code = StatusClientClosedRequest
 	}
 
-	// All errors land here, so it's a really good idea to do the logging here as well!
-	h.Reporter.ReportError(r, code, err, "An error occurred while handling a request")
+	if !o.noLog && !isNoLogError(err) {
+		// All errors land here, so it's a really good idea to do the logging here as well!
+		h.Reporter.ReportError(r, code, err, "An error occurred while handling a request")
+	}
+	err = localizeResponse(w, r, h.Catalog, err)
 
+	setErrorHeaders(w.Header(), err)
 	if id, ok := err.(interface{ ID() string }); ok {
 		w.Header().Set("Ory-Error-Id", id.ID())
 	}
 	w.Header().Set("Content-Type", h.contentType)
+	setDeprecationHeaders(w.Header(), r, h.Reporter)
 	w.WriteHeader(code)
-	fmt.Fprintf(w, "%s", err)
+
+	debug := debugEnabled(r, h.EnableDebug, h.DebugPolicy)
+	printDebug := debug
+	if h.ScrubPolicy != nil {
+		de := ToDefaultError(err, r.Header.Get("X-Request-ID"))
+		// Errors whose debug information is omitted are printed like without debug output.
+		printDebug = debug && !h.ScrubPolicy.omitsDebug(de)
+		err = h.ScrubPolicy.Scrub(de, debug)
+	}
+
+	// With debug output enabled, errors are printed including their stack trace.
+	if printDebug {
+		h.print(w, "%+v", err)
+	} else {
+		h.print(w, "%s", err)
+	}
+	for _, warning := range withDeprecationWarning(r, warningsOf(err)) {
+		h.print(w, "\nWarning: %s", warning)
+	}
+}
+
+// print writes v formatted using format to w, escaping it if the content type is HTML.
+// Use HTMLWriter to render proper HTML pages.
+func (h *TextWriter) print(w io.Writer, format string, v interface{}) {
+	if h.contentType == "text/html" {
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprintf(format, v)))
+		return
+	}
+	_, _ = fmt.Fprintf(w, format, v)
 }
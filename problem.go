@@ -0,0 +1,265 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// ProblemTypeBlank is the default problem type as defined in RFC 9457, Section 4.2.1.
+const ProblemTypeBlank = "about:blank"
+
+// Problem is a problem details object as defined in RFC 9457.
+//
+// All fields but the standard members `type`, `title`, `status`, `detail` and
+// `instance` are extension members carrying the same information as the
+// respective DefaultError fields.
+type Problem struct {
+	// A URI reference that identifies the problem type.
+	//
+	// example: about:blank
+	Type string `json:"type,omitempty"`
+
+	// A short, human-readable summary of the problem type.
+	//
+	// example: Not Found
+	Title string `json:"title,omitempty"`
+
+	// The HTTP status code generated by the origin server for this occurrence of the problem.
+	//
+	// example: 404
+	Status int `json:"status,omitempty"`
+
+	// A human-readable explanation specific to this occurrence of the problem.
+	//
+	// example: The requested resource could not be found
+	Detail string `json:"detail,omitempty"`
+
+	// A URI reference that identifies the specific occurrence of the problem.
+	//
+	// example: /users/1234
+	Instance string `json:"instance,omitempty"`
+
+	// The error ID
+	ID string `json:"id,omitempty"`
+
+	// A human-readable reason for the error
+	Reason string `json:"reason,omitempty"`
+
+	// The request ID
+	Request string `json:"request,omitempty"`
+
+	// The domain of the error ID
+	Domain string `json:"domain,omitempty"`
+
+	// Further error details
+	Details map[string]interface{} `json:"details,omitempty"`
+
+	// The fields of the request which failed validation
+	FieldViolations []FieldViolation `json:"field_violations,omitempty"`
+
+	// The preconditions which failed
+	PreconditionViolations []PreconditionViolation `json:"precondition_violations,omitempty"`
+
+	// The quotas which were exceeded
+	QuotaViolations []QuotaViolation `json:"quota_violations,omitempty"`
+
+	// The resource which was accessed
+	ResourceInfo *ResourceInfo `json:"resource_info,omitempty"`
+
+	// Links to documentation about the error
+	HelpLinks []HelpLink `json:"help_links,omitempty"`
+
+	// An error message which is safe to show to the end user
+	LocalizedMessage *LocalizedMessage `json:"localized_message,omitempty"`
+
+	// Warnings for the client
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Debug information
+	Debug string `json:"debug,omitempty"`
+
+	// The stack trace, if debug output is enabled
+	Stack []StackFrame `json:"stack,omitempty"`
+
+	// The chain of wrapped errors, if debug output is enabled
+	Causes []ErrorCause `json:"causes,omitempty"`
+}
+
+// ProblemWriter writes errors as RFC 9457 problem details (application/problem+json).
+// Responses which are not errors are written as regular JSON.
+//
+// Unlike JSONWriter, it has no ErrorEnhancer, as the payload is always a Problem. Use
+// ToProblem to build the problem details yourself if you need to customize them.
+type ProblemWriter struct {
+	Reporter    ErrorReporter
+	EnableDebug bool
+
+	// TypeBaseURI, if set, is used to build the problem type of errors
+	// which have an ID by appending the ID to it. Errors without an ID
+	// always use ProblemTypeBlank.
+	TypeBaseURI string
+
+	// DebugPolicy, if set, enables debug output for individual requests
+	// even if EnableDebug is false.
+	DebugPolicy DebugPolicy
+
+	// ScrubPolicy, if set, scrubs errors before they are written.
+	ScrubPolicy *ScrubPolicy
+
+	// DebugTrace configures the stack trace and causes added to errors
+	// if debug output is enabled.
+	DebugTrace DebugTraceOptions
+
+	// Catalog, if set, translates error messages into the language
+	// negotiated from the Accept-Language header.
+	Catalog Catalog
+}
+
+var _ Writer = (*ProblemWriter)(nil)
+
+// NewProblemWriter returns a writer for RFC 9457 problem details.
+func NewProblemWriter(reporter ErrorReporter) *ProblemWriter {
+	writer := &ProblemWriter{
+		Reporter: reporter,
+	}
+	if writer.Reporter == nil {
+		writer.Reporter = &stdReporter{}
+	}
+	return writer
+}
+
+// ToProblem converts an error into a problem details object.
+func (h *ProblemWriter) ToProblem(r *http.Request, code int, err error) *Problem {
+	debug := debugEnabled(r, h.EnableDebug, h.DebugPolicy)
+	de := ToDefaultError(err, r.Header.Get("X-Request-ID"))
+	if debug {
+		de.WithDebugTrace(h.DebugTrace)
+	}
+	de.WarningsField = withDeprecationWarning(r, de.WarningsField)
+	de = h.ScrubPolicy.Scrub(de, debug)
+
+	p := &Problem{
+		Type:                   ProblemTypeBlank,
+		Title:                  de.Status(),
+		Status:                 code,
+		Detail:                 de.Error(),
+		ID:                     de.ID(),
+		Reason:                 de.Reason(),
+		Request:                de.RequestID(),
+		Domain:                 de.Domain(),
+		Details:                de.Details(),
+		FieldViolations:        de.FieldViolations(),
+		PreconditionViolations: de.PreconditionViolations(),
+		QuotaViolations:        de.QuotaViolations(),
+		ResourceInfo:           de.ResourceInfo(),
+		HelpLinks:              de.HelpLinks(),
+		LocalizedMessage:       de.LocalizedMessage(),
+		Warnings:               de.Warnings(),
+	}
+	if h.TypeBaseURI != "" && de.ID() != "" {
+		p.Type = h.TypeBaseURI + de.ID()
+	}
+	if p.Type == ProblemTypeBlank || p.Title == "" {
+		// RFC 9457, Section 4.2.1: the title should be the same as the
+		// recommended HTTP status phrase for that code.
+		p.Title = http.StatusText(code)
+	}
+	if r.URL != nil {
+		p.Instance = r.URL.RequestURI()
+	}
+	if len(p.Details) == 0 {
+		p.Details = nil
+	}
+	if debug {
+		p.Debug = de.Debug()
+		p.Stack = de.Stack()
+		p.Causes = de.Causes()
+	}
+
+	return p
+}
+
+// Write a response object to the ResponseWriter with status code 200.
+func (h *ProblemWriter) Write(w http.ResponseWriter, r *http.Request, e interface{}, opts ...EncoderOptions) {
+	h.WriteCode(w, r, http.StatusOK, e, opts...)
+}
+
+// WriteCode writes a response object to the ResponseWriter and sets a response code.
+func (h *ProblemWriter) WriteCode(w http.ResponseWriter, r *http.Request, code int, e interface{}, opts ...EncoderOptions) {
+	bs := new(bytes.Buffer)
+	enc := json.NewEncoder(bs)
+	for _, opt := range opts {
+		opt(enc)
+	}
+
+	if err := enc.Encode(e); err != nil {
+		h.WriteError(w, r, errors.WithStack(err))
+		return
+	}
+
+	if code == 0 {
+		code = http.StatusOK
+	}
+
+	if errors.Is(r.Context().Err(), context.Canceled) {
+		code = StatusClientClosedRequest
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	setDeprecationHeaders(w.Header(), r, h.Reporter)
+	w.WriteHeader(code)
+	_, _ = w.Write(bs.Bytes())
+}
+
+// WriteCreated writes a response object to the ResponseWriter with status code 201 and
+// the Location header set to location.
+func (h *ProblemWriter) WriteCreated(w http.ResponseWriter, r *http.Request, location string, e interface{}) {
+	w.Header().Set("Location", location)
+	h.WriteCode(w, r, http.StatusCreated, e)
+}
+
+// WriteError writes an error to ResponseWriter and tries to extract the error's status code by
+// asserting statusCodeCarrier. If the error does not implement statusCodeCarrier, the status code
+// is set to 500.
+func (h *ProblemWriter) WriteError(w http.ResponseWriter, r *http.Request, err error, opts ...Option) {
+	h.WriteErrorCode(w, r, statusCodeOf(err), err, opts...)
+}
+
+// WriteErrorCode writes an error to ResponseWriter and forces an error code.
+func (h *ProblemWriter) WriteErrorCode(w http.ResponseWriter, r *http.Request, code int, err error, opts ...Option) {
+	o := newOptions(opts)
+	err = coalesceError(err)
+
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+
+	if errors.Is(r.Context().Err(), context.Canceled) {
+		code = StatusClientClosedRequest
+	}
+
+	if !o.noLog && !isNoLogError(err) {
+		// All errors land here, so it's a really good idea to do the logging here as well!
+		h.Reporter.ReportError(r, code, err, "An error occurred while handling a request")
+	}
+	err = localizeResponse(w, r, h.Catalog, err)
+
+	p := h.ToProblem(r, code, err)
+	setErrorHeaders(w.Header(), err)
+	w.Header().Set("Ory-Error-Id", p.ID)
+	w.Header().Set("Content-Type", "application/problem+json")
+	setDeprecationHeaders(w.Header(), r, h.Reporter)
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		// There was an error, but there's actually not a lot we can do except log that this happened.
+		h.Reporter.ReportError(r, code, errors.WithStack(err), "Could not write Problem to response writer")
+	}
+}
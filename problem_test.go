@@ -0,0 +1,150 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemWriter(t *testing.T) {
+	for k, tc := range []struct {
+		name           string
+		err            error
+		debug          bool
+		typeBaseURI    string
+		expect         Problem
+		expectedHeader string
+	}{
+		{
+			name: "default error",
+			err:  ErrNotFound().WithReason("User 1234 does not exist").WithDetail("user", "1234").WithDebug("sql: no rows"),
+			expect: Problem{
+				Type:     ProblemTypeBlank,
+				Title:    "Not Found",
+				Status:   http.StatusNotFound,
+				Detail:   "The requested resource could not be found",
+				Instance: "/users/1234?foo=bar",
+				Reason:   "User 1234 does not exist",
+				Request:  "request-id",
+				Details:  map[string]interface{}{"user": "1234"},
+			},
+		},
+		{
+			name: "with error details",
+			err: ErrPreconditionFailed().
+				WithDomain("kratos.ory.sh").
+				WithPreconditionViolation("TOS", "ory.sh", "Terms of service not accepted").
+				WithQuotaViolation("project:1234", "Daily limit exceeded").
+				WithResourceInfo(ResourceInfo{ResourceType: "identity", ResourceName: "1234"}).
+				WithHelpLink("Terms of service", "https://www.ory.sh/tos").
+				WithLocalizedMessage("en-US", "Please accept the terms of service"),
+			expect: Problem{
+				Type:                   ProblemTypeBlank,
+				Title:                  "Precondition Failed",
+				Status:                 http.StatusPreconditionFailed,
+				Detail:                 "One or more preconditions of the request are not met",
+				Instance:               "/users/1234?foo=bar",
+				Request:                "request-id",
+				Domain:                 "kratos.ory.sh",
+				PreconditionViolations: []PreconditionViolation{{Type: "TOS", Subject: "ory.sh", Description: "Terms of service not accepted"}},
+				QuotaViolations:        []QuotaViolation{{Subject: "project:1234", Description: "Daily limit exceeded"}},
+				ResourceInfo:           &ResourceInfo{ResourceType: "identity", ResourceName: "1234"},
+				HelpLinks:              []HelpLink{{Description: "Terms of service", URL: "https://www.ory.sh/tos"}},
+				LocalizedMessage:       &LocalizedMessage{Locale: "en-US", Message: "Please accept the terms of service"},
+			},
+		},
+		{
+			name:  "with debug",
+			err:   errors.WithStack(ErrNotFound().WithDebug("sql: no rows")),
+			debug: true,
+			expect: Problem{
+				Type:     ProblemTypeBlank,
+				Title:    "Not Found",
+				Status:   http.StatusNotFound,
+				Detail:   "The requested resource could not be found",
+				Instance: "/users/1234?foo=bar",
+				Request:  "request-id",
+				Debug:    "sql: no rows",
+				Causes: []ErrorCause{
+					{Message: "The requested resource could not be found", Type: "*errors.withStack"},
+					{Message: "The requested resource could not be found", Type: "*herodot.DefaultError"},
+				},
+			},
+		},
+		{
+			name:        "with type base URI",
+			err:         ErrMisconfiguration(),
+			typeBaseURI: "https://www.ory.sh/docs/errors/",
+			expect: Problem{
+				Type:     "https://www.ory.sh/docs/errors/invalid_configuration",
+				Title:    "Internal Server Error",
+				Status:   http.StatusInternalServerError,
+				Detail:   "Invalid configuration",
+				Instance: "/users/1234?foo=bar",
+				ID:       "invalid_configuration",
+				Reason:   "One or more configuration values are invalid. Please report this to the system administrator.",
+				Request:  "request-id",
+			},
+			expectedHeader: "invalid_configuration",
+		},
+		{
+			name: "plain error",
+			err:  errors.New("foo"),
+			expect: Problem{
+				Type:     ProblemTypeBlank,
+				Title:    "Internal Server Error",
+				Status:   http.StatusInternalServerError,
+				Detail:   "foo",
+				Instance: "/users/1234?foo=bar",
+				Request:  "request-id",
+			},
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/%s", k, tc.name), func(t *testing.T) {
+			h := NewProblemWriter(nil)
+			h.EnableDebug = tc.debug
+			h.TypeBaseURI = tc.typeBaseURI
+
+			rec := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/users/1234?foo=bar", nil)
+			r.Header.Set("X-Request-ID", "request-id")
+			h.WriteError(rec, r, tc.err)
+
+			assert.Equal(t, tc.expect.Status, rec.Code)
+			assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+			assert.Equal(t, tc.expectedHeader, rec.Header().Get("Ory-Error-Id"))
+
+			jsonRec := httptest.NewRecorder()
+			NewJSONWriter(nil).WriteError(jsonRec, r, tc.err)
+			assert.Equal(t, jsonRec.Header().Values("Ory-Error-Id"), rec.Header().Values("Ory-Error-Id"), "the header must be set like by the JSONWriter")
+
+			var actual Problem
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&actual))
+			if tc.debug {
+				require.NotEmpty(t, actual.Stack)
+				assert.Equal(t, "github.com/ory/herodot.TestProblemWriter", actual.Stack[0].Function)
+				actual.Stack = nil
+			}
+			assert.Equal(t, tc.expect, actual)
+		})
+	}
+
+	t.Run("case=write", func(t *testing.T) {
+		h := NewProblemWriter(nil)
+		rec := httptest.NewRecorder()
+		h.WriteCode(rec, httptest.NewRequest("GET", "/", nil), http.StatusAccepted, map[string]string{"foo": "bar"})
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"foo":"bar"}`, rec.Body.String())
+	})
+}
@@ -0,0 +1,172 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicError converts a recovered panic value into ErrInternalServerError, wrapping the panic value
+// if it is an error. The panic value and the stack of the panicking goroutine are added as debug
+// information, so call it in the deferred function that recovered the panic.
+func PanicError(p interface{}) *DefaultError {
+	err, ok := p.(error)
+	if !ok {
+		err = fmt.Errorf("%v", p)
+	}
+	return ErrInternalServerError().WithTrace(err).WithDebugf("panic: %v\n\n%s", p, debug.Stack())
+}
+
+// discardedPanicHeaders lists the headers describing the response the handler intended to write,
+// which do not apply to the error written instead.
+var discardedPanicHeaders = []string{
+	"Cache-Control",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Length",
+	"Content-Location",
+	"Content-Range",
+	"ETag",
+	"Expires",
+	"Last-Modified",
+	"Location",
+}
+
+// RecoveryOption configures RecoveryMiddleware.
+type RecoveryOption func(*recoveryOptions)
+
+type recoveryOptions struct {
+	reporter ErrorReporter
+	convert  func(r *http.Request, p interface{}) error
+}
+
+// RecoveryReporter sets the reporter of panics which occur after the response has started and thus
+// can not be written. Defaults to the reporter of the Writer passed to RecoveryMiddleware, or to
+// printing to stdout like the writers do without a reporter if it has none.
+func RecoveryReporter(reporter ErrorReporter) RecoveryOption {
+	return func(o *recoveryOptions) {
+		o.reporter = reporter
+	}
+}
+
+// RecoveryConverter sets the function converting a recovered panic value into the written error.
+// Defaults to PanicError.
+func RecoveryConverter(convert func(r *http.Request, p interface{}) error) RecoveryOption {
+	return func(o *recoveryOptions) {
+		o.convert = convert
+	}
+}
+
+// RecoveryMiddleware recovers panics of the handler and writes them as errors using w, which also
+// reports them. If the response has already started or the connection was hijacked, the panic is
+// reported using the reporter of w, see RecoveryReporter, and the connection is aborted instead.
+// http.ErrAbortHandler is not recovered, as it is used to abort handlers on purpose.
+func RecoveryMiddleware(w Writer, opts ...RecoveryOption) func(http.Handler) http.Handler {
+	o := &recoveryOptions{reporter: reporterOf(w)}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.reporter == nil {
+		o.reporter = &stdReporter{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rrw := &recoveryResponseWriter{ResponseWriter: rw}
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+
+				var err error
+				if o.convert != nil {
+					err = o.convert(r, p)
+				} else {
+					err = PanicError(p)
+				}
+
+				if rrw.started {
+					if !isNoLogError(err) {
+						o.reporter.ReportError(r, statusCodeOf(err), err, "A panic occurred after the response was started")
+					}
+					panic(http.ErrAbortHandler)
+				}
+
+				// The handler might have described the response it intended to write.
+				for _, h := range discardedPanicHeaders {
+					rw.Header().Del(h)
+				}
+				w.WriteError(rw, r, err)
+			}()
+			next.ServeHTTP(rrw, r)
+		})
+	}
+}
+
+// reporterOf returns the reporter of the writers of this package, or nil if w has none.
+func reporterOf(w Writer) ErrorReporter {
+	switch w := w.(type) {
+	case *JSONWriter:
+		return w.Reporter
+	case *TextWriter:
+		return w.Reporter
+	case *HTMLWriter:
+		return w.Reporter
+	case *ProblemWriter:
+		return w.Reporter
+	case *NegotiationHandler:
+		return w.reporter
+	case *VersionedWriter:
+		return reporterOf(w.Writer)
+	}
+	return nil
+}
+
+// recoveryResponseWriter tracks whether the response has started or the connection was hijacked.
+type recoveryResponseWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *recoveryResponseWriter) WriteHeader(code int) {
+	// Informational responses do not start the final response.
+	if code >= http.StatusOK || code == http.StatusSwitchingProtocols {
+		w.started = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *recoveryResponseWriter) Write(b []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (w *recoveryResponseWriter) Flush() {
+	w.started = true
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker.
+func (w *recoveryResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.started = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying ResponseWriter for use with http.ResponseController.
+func (w *recoveryResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
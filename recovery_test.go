@@ -0,0 +1,184 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type syncReporter struct {
+	mu    sync.Mutex
+	codes []int
+	errs  []error
+}
+
+func (r *syncReporter) ReportError(_ *http.Request, code int, err error, _ ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.codes = append(r.codes, code)
+	r.errs = append(r.errs, err)
+}
+
+func (r *syncReporter) reported() ([]int, []error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.codes, r.errs
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	t.Run("case=writes the error", func(t *testing.T) {
+		reporter := new(syncReporter)
+		h := RecoveryMiddleware(NewJSONWriter(reporter))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", "1234")
+			panic("something bad happened")
+		}))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Length"))
+
+		var ec ErrorContainer
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&ec))
+		assert.Equal(t, ErrInternalServerError().Error(), ec.Error.Error())
+		assert.Empty(t, ec.Error.Debug())
+
+		codes, errs := reporter.reported()
+		assert.Equal(t, []int{http.StatusInternalServerError}, codes)
+		require.Len(t, errs, 1)
+		assert.Contains(t, ToDefaultError(errs[0], "").Debug(), "panic: something bad happened")
+		assert.NotEmpty(t, ToDefaultError(errs[0], "").StackTrace())
+	})
+
+	t.Run("case=discards the headers of the intended response", func(t *testing.T) {
+		h := RecoveryMiddleware(NewJSONWriter(nil))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Header().Set("ETag", `"1234"`)
+			w.Header().Set("Location", "/users/1234")
+			w.Header().Set("X-Request-Id", "request-id")
+			panic("something bad happened")
+		}))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Empty(t, rec.Header().Values("Content-Encoding"))
+		assert.Empty(t, rec.Header().Values("ETag"))
+		assert.Empty(t, rec.Header().Values("Location"))
+		assert.Equal(t, "request-id", rec.Header().Get("X-Request-Id"))
+
+		var ec ErrorContainer
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&ec), "the body must not be declared as gzip")
+	})
+
+	t.Run("case=custom conversion", func(t *testing.T) {
+		h := RecoveryMiddleware(NewTextWriter(nil, "plain"), RecoveryConverter(func(_ *http.Request, p interface{}) error {
+			return ErrServiceUnavailable().WithReasonf("%v", p)
+		}))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic("shutting down")
+		}))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, ErrServiceUnavailable().Error(), rec.Body.String())
+	})
+
+	t.Run("case=aborts started responses", func(t *testing.T) {
+		writerReporter, reporter := new(syncReporter), new(syncReporter)
+		ts := httptest.NewServer(RecoveryMiddleware(NewJSONWriter(writerReporter), RecoveryReporter(reporter))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"foo":`))
+			w.(http.Flusher).Flush()
+			panic("something bad happened")
+		})))
+		t.Cleanup(ts.Close)
+
+		res, err := ts.Client().Get(ts.URL)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		_, err = io.ReadAll(res.Body)
+		assert.Error(t, err, "the connection must be aborted")
+
+		codes, _ := reporter.reported()
+		assert.Equal(t, []int{http.StatusInternalServerError}, codes)
+		codes, _ = writerReporter.reported()
+		assert.Empty(t, codes)
+	})
+
+	t.Run("case=reports using the reporter of a negotiation handler", func(t *testing.T) {
+		reporter := new(syncReporter)
+		ts := httptest.NewServer(RecoveryMiddleware(NewNegotiationHandler(reporter))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			panic("something bad happened")
+		})))
+		t.Cleanup(ts.Close)
+
+		res, err := ts.Client().Get(ts.URL)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		_, _ = io.ReadAll(res.Body)
+
+		codes, _ := reporter.reported()
+		assert.Equal(t, []int{http.StatusInternalServerError}, codes)
+	})
+
+	t.Run("case=aborts hijacked connections", func(t *testing.T) {
+		reporter := new(syncReporter)
+		ts := httptest.NewServer(RecoveryMiddleware(NewJSONWriter(reporter))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			defer conn.Close()
+			_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nok")
+			_ = buf.Flush()
+			panic("something bad happened")
+		})))
+		t.Cleanup(ts.Close)
+
+		res, err := ts.Client().Get(ts.URL)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "ok", string(body))
+
+		require.EventuallyWithT(t, func(t *assert.CollectT) {
+			codes, _ := reporter.reported()
+			assert.Equal(t, []int{http.StatusInternalServerError}, codes)
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("case=does not recover ErrAbortHandler", func(t *testing.T) {
+		h := RecoveryMiddleware(NewJSONWriter(nil))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		})
+	})
+
+	t.Run("case=passes through", func(t *testing.T) {
+		h := RecoveryMiddleware(NewJSONWriter(nil))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			NewJSONWriter(nil).Write(w, r, map[string]string{"foo": "bar"})
+		}))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"foo":"bar"}`, rec.Body.String())
+	})
+}
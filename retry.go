@@ -0,0 +1,190 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/ory/herodot/httputil/header"
+)
+
+// RateLimit describes the quota state of a rate-limited resource. It is written as
+// the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers
+// defined by the IETF RateLimit header fields draft, and as errdetails.QuotaFailure once
+// the quota is exhausted.
+type RateLimit struct {
+	// Limit is the number of requests allowed in the current window.
+	Limit int
+
+	// Remaining is the number of requests remaining in the current window.
+	Remaining int
+
+	// Reset is the time until the current window ends.
+	Reset time.Duration
+
+	// Window is the length of the window of the quota policy. Optional.
+	Window time.Duration
+
+	// Policy identifies the quota policy, e.g. "requests_per_minute". Optional.
+	Policy string
+
+	// Subject identifies the entity the quota applies to, e.g. "clientip:127.0.0.1". Optional.
+	Subject string
+}
+
+func (rl *RateLimit) clone() *RateLimit {
+	if rl == nil {
+		return nil
+	}
+	c := *rl
+	return &c
+}
+
+// RetryAfterCarrier can be implemented by an error to tell clients when to retry the request.
+type RetryAfterCarrier interface {
+	// RetryAfter returns the time after which the request may be retried, if applicable.
+	RetryAfter() time.Duration
+}
+
+// RateLimitCarrier can be implemented by an error to tell clients about their quota.
+type RateLimitCarrier interface {
+	// RateLimit returns the quota state, if applicable.
+	RateLimit() *RateLimit
+}
+
+// RetryAfter returns the time after which the request may be retried.
+func (e *DefaultError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// WithRetryAfter sets the time after which the request may be retried. Mutates and returns the receiver.
+func (e *DefaultError) WithRetryAfter(d time.Duration) *DefaultError {
+	e.retryAfter = d
+	return e
+}
+
+// RateLimit returns the quota state.
+func (e *DefaultError) RateLimit() *RateLimit {
+	return e.rateLimit
+}
+
+// WithRateLimit sets the quota state. Mutates and returns the receiver.
+func (e *DefaultError) WithRateLimit(rl RateLimit) *DefaultError {
+	e.rateLimit = &rl
+	return e
+}
+
+// seconds rounds d up to full seconds.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10)
+}
+
+// retryAfterOf returns the retry delay of the first carrier in the error's chain which has one, so that
+// it is not hidden by wrapping errors which implement RetryAfterCarrier but do not have a delay.
+func retryAfterOf(err error) (d time.Duration) {
+	walkErrors(err, func(err error) {
+		if c, ok := err.(RetryAfterCarrier); ok && d <= 0 {
+			d = c.RetryAfter()
+		}
+	})
+	return max(d, 0)
+}
+
+// rateLimitOf returns the quota state of the first carrier in the error's chain which has one.
+func rateLimitOf(err error) (rl *RateLimit) {
+	walkErrors(err, func(err error) {
+		if c, ok := err.(RateLimitCarrier); ok && rl == nil {
+			rl = c.RateLimit()
+		}
+	})
+	return
+}
+
+// setRetryHeaders sets the Retry-After and RateLimit-* headers for err.
+func setRetryHeaders(h http.Header, err error) {
+	if d := retryAfterOf(err); d > 0 {
+		h.Set("Retry-After", seconds(d))
+	}
+	if rl := rateLimitOf(err); rl != nil {
+		h.Set("RateLimit-Limit", strconv.Itoa(rl.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(rl.Remaining))
+		h.Set("RateLimit-Reset", seconds(rl.Reset))
+		if rl.Window > 0 {
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", rl.Limit, seconds(rl.Window)))
+		}
+	}
+}
+
+// parseRetryHeaders restores retry information set by setRetryHeaders.
+func parseRetryHeaders(de *DefaultError, h http.Header) {
+	if v := h.Get("Retry-After"); v != "" {
+		if s, err := strconv.Atoi(v); err == nil {
+			de.retryAfter = time.Duration(s) * time.Second
+		} else if t := header.ParseTime(h, "Retry-After"); !t.IsZero() {
+			de.retryAfter = time.Until(t)
+		}
+	}
+
+	limit, err := strconv.Atoi(h.Get("RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	rl := &RateLimit{Limit: limit}
+	rl.Remaining, _ = strconv.Atoi(h.Get("RateLimit-Remaining"))
+	if s, err := strconv.Atoi(h.Get("RateLimit-Reset")); err == nil {
+		rl.Reset = time.Duration(s) * time.Second
+	}
+	if _, w, ok := strings.Cut(h.Get("RateLimit-Policy"), ";w="); ok {
+		w, _, _ = strings.Cut(w, ";")
+		if s, err := strconv.Atoi(w); err == nil {
+			rl.Window = time.Duration(s) * time.Second
+		}
+	}
+	de.rateLimit = rl
+}
+
+// The quota dimensions carrying the reset and window of a rate limit in gRPC status details.
+const (
+	quotaDimensionReset  = "ratelimit_reset"
+	quotaDimensionWindow = "ratelimit_window"
+)
+
+// retryDetails returns the gRPC status details for the retry information of e.
+func (e *DefaultError) retryDetails() (details []protoadapt.MessageV1) {
+	if e.retryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(e.retryAfter)})
+	}
+	qf := &errdetails.QuotaFailure{}
+	if rl := e.rateLimit; rl != nil && rl.Remaining <= 0 {
+		v := &errdetails.QuotaFailure_Violation{
+			Subject:         rl.Subject,
+			Description:     fmt.Sprintf("Quota of %d requests exhausted, resets in %s", rl.Limit, rl.Reset),
+			QuotaId:         rl.Policy,
+			QuotaValue:      int64(rl.Limit),
+			QuotaDimensions: map[string]string{quotaDimensionReset: rl.Reset.String()},
+		}
+		if rl.Window > 0 {
+			v.QuotaDimensions[quotaDimensionWindow] = rl.Window.String()
+		}
+		qf.Violations = append(qf.Violations, v)
+	}
+	for _, v := range e.QuotaViolationsField {
+		qf.Violations = append(qf.Violations, &errdetails.QuotaFailure_Violation{
+			Subject:     v.Subject,
+			Description: v.Description,
+		})
+	}
+	if len(qf.Violations) > 0 {
+		details = append(details, qf)
+	}
+	return
+}
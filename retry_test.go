@@ -0,0 +1,134 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func rateLimitedError() *DefaultError {
+	return ErrTooManyRequests().
+		WithRetryAfter(1500 * time.Millisecond).
+		WithRateLimit(RateLimit{Limit: 100, Remaining: 0, Reset: 30 * time.Second, Window: time.Minute, Policy: "requests_per_minute", Subject: "clientip:127.0.0.1"})
+}
+
+func TestRetryHeaders(t *testing.T) {
+	for k, w := range []Writer{
+		NewJSONWriter(nil),
+		NewTextWriter(nil, "plain"),
+		NewProblemWriter(nil),
+		NewHTMLWriter(nil),
+		NewNegotiationHandler(nil),
+	} {
+		t.Run(fmt.Sprintf("case=%d/%T", k, w), func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			w.WriteError(rec, httptest.NewRequest("GET", "/", nil), errors.WithStack(rateLimitedError()))
+
+			assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+			assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+			assert.Equal(t, "100", rec.Header().Get("RateLimit-Limit"))
+			assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+			assert.Equal(t, "30", rec.Header().Get("RateLimit-Reset"))
+			assert.Equal(t, "100;w=60", rec.Header().Get("RateLimit-Policy"))
+		})
+	}
+
+	t.Run("case=no retry information", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewJSONWriter(nil).WriteError(rec, httptest.NewRequest("GET", "/", nil), ErrNotFound())
+
+		assert.Empty(t, rec.Header().Get("Retry-After"))
+		assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+	})
+}
+
+func TestRetryGRPCStatus(t *testing.T) {
+	s := rateLimitedError().GRPCStatus()
+
+	require.Len(t, s.Details(), 2)
+	retry, ok := s.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	assert.Equal(t, 1500*time.Millisecond, retry.RetryDelay.AsDuration())
+	quota, ok := s.Details()[1].(*errdetails.QuotaFailure)
+	require.True(t, ok)
+	require.Len(t, quota.Violations, 1)
+	assert.Equal(t, "clientip:127.0.0.1", quota.Violations[0].Subject)
+	assert.Equal(t, "requests_per_minute", quota.Violations[0].QuotaId)
+	assert.EqualValues(t, 100, quota.Violations[0].QuotaValue)
+
+	t.Run("case=quota not exhausted", func(t *testing.T) {
+		s := ErrServiceUnavailable().WithRetryAfter(time.Second).WithRateLimit(RateLimit{Limit: 100, Remaining: 10}).GRPCStatus()
+		assert.Len(t, s.Details(), 1)
+	})
+}
+
+func TestRetryClientSide(t *testing.T) {
+	t.Run("case=http", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewJSONWriter(nil).WriteError(rec, httptest.NewRequest("GET", "/", nil), rateLimitedError())
+
+		var de *DefaultError
+		require.ErrorAs(t, ErrorFromResponse(rec.Result()), &de)
+		assert.Equal(t, 2*time.Second, de.RetryAfter())
+		assert.Equal(t, &RateLimit{Limit: 100, Remaining: 0, Reset: 30 * time.Second, Window: time.Minute}, de.RateLimit())
+	})
+
+	t.Run("case=grpc", func(t *testing.T) {
+		de := FromGRPCStatus(rateLimitedError().GRPCStatus())
+		assert.Equal(t, 1500*time.Millisecond, de.RetryAfter())
+		assert.Equal(t, rateLimitedError().RateLimit(), de.RateLimit())
+	})
+
+	t.Run("case=grpc with foreign quota violations", func(t *testing.T) {
+		s, err := status.New(codes.ResourceExhausted, "slow down").WithDetails(
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Second)},
+			&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{
+				{Subject: "project:1", Description: "too many projects", QuotaId: "projects_per_org", QuotaValue: 10},
+				{Subject: "project:2", Description: "too many users", QuotaId: "users_per_project", QuotaValue: 100},
+			}},
+		)
+		require.NoError(t, err)
+
+		de := FromGRPCStatus(s)
+		assert.Nil(t, de.RateLimit())
+		assert.Equal(t, time.Second, de.RetryAfter())
+		assert.Equal(t, []QuotaViolation{
+			{Subject: "project:1", Description: "too many projects"},
+			{Subject: "project:2", Description: "too many users"},
+		}, de.QuotaViolations())
+	})
+
+	t.Run("case=wrapped carriers", func(t *testing.T) {
+		err := ErrServiceUnavailable().WithWrap(rateLimitedError())
+
+		rec := httptest.NewRecorder()
+		NewJSONWriter(nil).WriteError(rec, httptest.NewRequest("GET", "/", nil), err)
+		assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+		assert.Equal(t, "100", rec.Header().Get("RateLimit-Limit"))
+
+		de := ToDefaultError(err, "")
+		assert.Equal(t, 1500*time.Millisecond, de.RetryAfter())
+		assert.Equal(t, rateLimitedError().RateLimit(), de.RateLimit())
+	})
+
+	t.Run("case=clone", func(t *testing.T) {
+		orig := rateLimitedError()
+		c := orig.Clone()
+		c.RateLimit().Remaining = 5
+		assert.Equal(t, 0, orig.RateLimit().Remaining)
+		assert.Equal(t, orig.RetryAfter(), c.RetryAfter())
+	})
+}
@@ -0,0 +1,278 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"regexp"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// Common masks for ScrubRule.Masks.
+var (
+	// ScrubMaskEmail masks email addresses.
+	ScrubMaskEmail = ScrubMask{
+		Pattern:     regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		Replacement: "[email]",
+	}
+
+	// ScrubMaskAuthorization masks credentials of Bearer and Basic authorization.
+	ScrubMaskAuthorization = ScrubMask{
+		Pattern:     regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/-]+=*`),
+		Replacement: "$1 [redacted]",
+	}
+
+	// ScrubMaskURLCredentials masks the user information of URLs, e.g. of database connection strings.
+	ScrubMaskURLCredentials = ScrubMask{
+		Pattern:     regexp.MustCompile(`\b([A-Za-z][A-Za-z0-9+.-]*://)[^/?#@\s]+@`),
+		Replacement: "${1}[redacted]@",
+	}
+)
+
+// ScrubMask replaces all matches of Pattern with Replacement, which may reference
+// submatches as described in regexp.Regexp.Expand.
+type ScrubMask struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// ScrubRule scrubs errors matching all of its conditions. A rule without conditions
+// matches all errors.
+type ScrubRule struct {
+	// MinCode and MaxCode, if set, limit the rule to errors with an HTTP status code
+	// in the closed interval [MinCode, MaxCode].
+	MinCode int
+	MaxCode int
+
+	// IDs, if set, limits the rule to errors with one of the IDs.
+	IDs []string
+
+	// Message, if set, replaces the error message.
+	Message string
+
+	// OmitReason removes the reason.
+	OmitReason bool
+
+	// OmitDebug removes the debug information, the stack trace and the causes.
+	OmitDebug bool
+
+	// OmitDetails removes all details.
+	OmitDetails bool
+
+	// OmitErrorDetails removes the field violations, failed preconditions, quota violations,
+	// resource info and help links.
+	OmitErrorDetails bool
+
+	// OmitDetailKeys removes the details with the keys.
+	OmitDetailKeys []string
+
+	// DebugOnlyDetailKeys removes the details with the keys unless debug output is enabled.
+	DebugOnlyDetailKeys []string
+
+	// Masks are applied to the message, the localized message, the reason, the warnings, the
+	// debug information, the messages of the causes, all string values of the details and all
+	// text of the field violations, failed preconditions, quota violations, resource info and
+	// help links.
+	Masks []ScrubMask
+}
+
+func (r *ScrubRule) matches(de *DefaultError) bool {
+	code := de.StatusCode()
+	if r.MinCode != 0 && code < r.MinCode {
+		return false
+	}
+	if r.MaxCode != 0 && code > r.MaxCode {
+		return false
+	}
+	if len(r.IDs) > 0 && !slices.Contains(r.IDs, de.ID()) {
+		return false
+	}
+	return true
+}
+
+func (r *ScrubRule) apply(de *DefaultError, debug bool) {
+	if r.Message != "" {
+		de.ErrorField = r.Message
+		de.LocalizedMessageField = nil
+	}
+	if r.OmitReason {
+		de.ReasonField = ""
+	}
+	if r.OmitDebug {
+		de.DebugField = ""
+		de.StackField = nil
+		de.CausesField = nil
+	}
+	if r.OmitDetails {
+		de.DetailsField = nil
+	}
+	if r.OmitErrorDetails {
+		de.FieldViolationsField = nil
+		de.PreconditionViolationsField = nil
+		de.QuotaViolationsField = nil
+		de.ResourceInfoField = nil
+		de.HelpLinksField = nil
+	}
+	for _, key := range r.OmitDetailKeys {
+		delete(de.DetailsField, key)
+	}
+	if !debug {
+		for _, key := range r.DebugOnlyDetailKeys {
+			delete(de.DetailsField, key)
+		}
+	}
+
+	if len(r.Masks) == 0 {
+		return
+	}
+	de.ErrorField = r.mask(de.ErrorField)
+	if de.LocalizedMessageField != nil {
+		de.LocalizedMessageField.Message = r.mask(de.LocalizedMessageField.Message)
+	}
+	de.ReasonField = r.mask(de.ReasonField)
+	de.DebugField = r.mask(de.DebugField)
+	for i := range de.WarningsField {
+		de.WarningsField[i] = r.mask(de.WarningsField[i])
+	}
+	for i := range de.CausesField {
+		de.CausesField[i].Message = r.mask(de.CausesField[i].Message)
+	}
+	for i := range de.FieldViolationsField {
+		fv := &de.FieldViolationsField[i]
+		fv.Field = r.mask(fv.Field)
+		fv.Description = r.mask(fv.Description)
+		fv.Reason = r.mask(fv.Reason)
+	}
+	for i := range de.PreconditionViolationsField {
+		v := &de.PreconditionViolationsField[i]
+		v.Type = r.mask(v.Type)
+		v.Subject = r.mask(v.Subject)
+		v.Description = r.mask(v.Description)
+	}
+	for i := range de.QuotaViolationsField {
+		v := &de.QuotaViolationsField[i]
+		v.Subject = r.mask(v.Subject)
+		v.Description = r.mask(v.Description)
+	}
+	if ri := de.ResourceInfoField; ri != nil {
+		ri.ResourceType = r.mask(ri.ResourceType)
+		ri.ResourceName = r.mask(ri.ResourceName)
+		ri.Owner = r.mask(ri.Owner)
+		ri.Description = r.mask(ri.Description)
+	}
+	for i := range de.HelpLinksField {
+		l := &de.HelpLinksField[i]
+		l.Description = r.mask(l.Description)
+		l.URL = r.mask(l.URL)
+	}
+	for key, value := range de.DetailsField {
+		de.DetailsField[key] = r.maskValue(value)
+	}
+}
+
+func (r *ScrubRule) mask(s string) string {
+	for _, m := range r.Masks {
+		s = m.Pattern.ReplaceAllString(s, m.Replacement)
+	}
+	return s
+}
+
+func (r *ScrubRule) maskValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return r.mask(v)
+	case []string:
+		res := make([]string, len(v))
+		for i, s := range v {
+			res[i] = r.mask(s)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, e := range v {
+			res[i] = r.maskValue(e)
+		}
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			res[k] = r.maskValue(e)
+		}
+		return res
+	}
+	return v
+}
+
+// ScrubPolicy removes or masks sensitive information from errors before they are written
+// to clients. All matching rules are applied in order. Writers report errors before
+// scrubbing them, so reporters always see the unscrubbed error. For gRPC, pass the policy
+// to the sanitize interceptors using SanitizeScrubPolicy.
+type ScrubPolicy struct {
+	Rules []ScrubRule
+}
+
+// Scrub5xxPolicy returns a policy which removes the message, reason, details, error details
+// and debug information of server errors, like Scrub5xxJSONErrorEnhancer does.
+func Scrub5xxPolicy() *ScrubPolicy {
+	return &ScrubPolicy{Rules: []ScrubRule{{
+		MinCode:          500,
+		Message:          ErrInternalServerError().ErrorField,
+		OmitReason:       true,
+		OmitDebug:        true,
+		OmitDetails:      true,
+		OmitErrorDetails: true,
+	}}}
+}
+
+// omitsDebug returns true if a rule matching de omits the debug information.
+func (p *ScrubPolicy) omitsDebug(de *DefaultError) bool {
+	if p == nil {
+		return false
+	}
+	for i := range p.Rules {
+		if p.Rules[i].OmitDebug && p.Rules[i].matches(de) {
+			return true
+		}
+	}
+	return false
+}
+
+// scrubbedCause replaces the wrapped error of a scrubbed error. It only keeps the stack trace,
+// so that the field violations and messages of the wrapped error are not exposed.
+type scrubbedCause struct {
+	stack errors.StackTrace
+}
+
+func (c *scrubbedCause) Error() string                 { return "scrubbed" }
+func (c *scrubbedCause) StackTrace() errors.StackTrace { return c.stack }
+
+// Scrub returns a scrubbed copy of de. Debug-only details are kept if debug is true.
+// A nil policy returns de as is.
+//
+// The copy does not wrap the errors wrapped by de. Its field violations are taken from them
+// before the rules are applied, and its stack trace is kept unless a rule omits the debug
+// information.
+func (p *ScrubPolicy) Scrub(de *DefaultError, debug bool) *DefaultError {
+	if p == nil {
+		return de
+	}
+
+	stack := de.StackTrace()
+	de = de.Clone()
+	de.FieldViolationsField = de.FieldViolations()
+	de.err = nil
+	for i := range p.Rules {
+		if p.Rules[i].matches(de) {
+			p.Rules[i].apply(de, debug)
+			if p.Rules[i].OmitDebug {
+				stack = nil
+			}
+		}
+	}
+	if stack != nil {
+		de.err = &scrubbedCause{stack: stack}
+	}
+	return de
+}
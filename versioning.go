@@ -0,0 +1,240 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/ory/herodot/httputil"
+	"github.com/ory/herodot/httputil/header"
+)
+
+// VersionTransformer converts a response object into its representation in an API version.
+type VersionTransformer func(r *http.Request, e interface{}) (interface{}, error)
+
+// VersionedWriter serves several versions of a response from one handler. Each version
+// registers a VersionTransformer which converts the response object before it is written
+// by the underlying Writer.
+//
+// The version is selected by the media type parameter of the Accept header, e.g.
+// `application/json; version=2`, the request header, or the query parameter, in that order.
+// Only the media range matching the media type the Writer negotiates is considered, so that
+// `text/html; version=3, application/json; q=0.1` selects no version for a JSONWriter. The
+// media types of writers other than the ones in this package are not known; for them the
+// media range with the highest quality is used.
+// The selected version is added as media type parameter to the Content-Type of the response
+// and set as the response header. Requests for versions which are not registered are
+// answered with ErrUnsupportedVersion. Errors are written as they are.
+type VersionedWriter struct {
+	// Writer writes the transformed response objects and errors.
+	Writer Writer
+
+	// MediaTypeParameter is the media type parameter selecting the version. Defaults to "version".
+	MediaTypeParameter string
+
+	// Header, if set, is the request header selecting the version, e.g. "Ory-Api-Version".
+	Header string
+
+	// QueryParameter, if set, is the query parameter selecting the version, e.g. "version".
+	QueryParameter string
+
+	// DefaultVersion is used if the request does not select a version. If it is empty,
+	// the version registered last is used.
+	DefaultVersion string
+
+	versions     []string
+	transformers map[string]VersionTransformer
+}
+
+var _ Writer = (*VersionedWriter)(nil)
+
+// NewVersionedWriter creates a new VersionedWriter which writes responses using w and selects the
+// version using the "version" media type parameter.
+func NewVersionedWriter(w Writer) *VersionedWriter {
+	return &VersionedWriter{
+		Writer:             w,
+		MediaTypeParameter: "version",
+	}
+}
+
+// Register registers the transformer of a version. A nil transformer writes the response object
+// as it is. Registering a version twice replaces the previously registered transformer. Mutates
+// and returns the receiver.
+func (h *VersionedWriter) Register(version string, t VersionTransformer) *VersionedWriter {
+	if h.transformers == nil {
+		h.transformers = map[string]VersionTransformer{}
+	}
+	if _, ok := h.transformers[version]; !ok {
+		h.versions = append(h.versions, version)
+	}
+	h.transformers[version] = t
+	return h
+}
+
+// Versions returns the registered versions in order of registration.
+func (h *VersionedWriter) Versions() []string {
+	return slices.Clone(h.versions)
+}
+
+// requestedVersion returns the version selected by the request, or an empty string.
+func (h *VersionedWriter) requestedVersion(r *http.Request) string {
+	if h.MediaTypeParameter != "" {
+		if v := h.mediaTypeVersion(r); v != "" {
+			return v
+		}
+	}
+	if h.Header != "" {
+		if v := r.Header.Get(h.Header); v != "" {
+			return v
+		}
+	}
+	if h.QueryParameter != "" {
+		if v := r.URL.Query().Get(h.QueryParameter); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// mediaTypeVersion returns the media type parameter of the media range selected for the Writer.
+func (h *VersionedWriter) mediaTypeVersion(r *http.Request) string {
+	parameter := strings.ToLower(h.MediaTypeParameter)
+	if offers := mediaTypesOf(h.Writer); len(offers) > 0 {
+		if match, ok := httputil.NegotiateMediaType(r, offers); ok {
+			return match.Params[parameter]
+		}
+		return ""
+	}
+
+	bestQ := 0.0
+	version := ""
+	for _, spec := range header.ParseMediaRanges(r.Header, "Accept") {
+		if v, ok := spec.Params[parameter]; ok && spec.Q > bestQ {
+			version, bestQ = v, spec.Q
+		}
+	}
+	return version
+}
+
+// mediaTypesOf returns the media types the writer negotiates responses for, if they are known.
+func mediaTypesOf(w Writer) []string {
+	switch w := w.(type) {
+	case *JSONWriter, *ProblemWriter:
+		return []string{"application/json"}
+	case *TextWriter:
+		return []string{w.contentType}
+	case *HTMLWriter:
+		return []string{"text/html"}
+	case *NegotiationHandler:
+		return w.MediaTypes()
+	case *VersionedWriter:
+		return mediaTypesOf(w.Writer)
+	}
+	return nil
+}
+
+// transform transforms e into the version selected by the request. If the version is not
+// supported or the transformation fails, the error is written and false is returned.
+func (h *VersionedWriter) transform(w http.ResponseWriter, r *http.Request, e interface{}) (http.ResponseWriter, interface{}, bool) {
+	addVary(w.Header(), "Accept")
+	if h.Header != "" {
+		addVary(w.Header(), h.Header)
+	}
+
+	version := h.requestedVersion(r)
+	if version == "" {
+		version = h.DefaultVersion
+	}
+	if version == "" && len(h.versions) > 0 {
+		version = h.versions[len(h.versions)-1]
+	}
+
+	t, ok := h.transformers[version]
+	if !ok {
+		h.Writer.WriteError(w, r, ErrUnsupportedVersion().
+			WithReasonf("API version %q is not supported. The supported versions are: %s", version, strings.Join(h.versions, ", ")).
+			WithDetail("supported_versions", h.Versions()))
+		return nil, nil, false
+	}
+
+	if t != nil {
+		var err error
+		if e, err = t(r, e); err != nil {
+			h.Writer.WriteError(w, r, err)
+			return nil, nil, false
+		}
+	}
+
+	if h.Header != "" {
+		w.Header().Set(h.Header, version)
+	}
+	return &versionedResponseWriter{ResponseWriter: w, parameter: h.MediaTypeParameter, version: version}, e, true
+}
+
+// Write a response object to the ResponseWriter with status code 200.
+func (h *VersionedWriter) Write(w http.ResponseWriter, r *http.Request, e interface{}, opts ...EncoderOptions) {
+	if w, e, ok := h.transform(w, r, e); ok {
+		h.Writer.Write(w, r, e, opts...)
+	}
+}
+
+// WriteCode writes a response object to the ResponseWriter and sets a response code.
+func (h *VersionedWriter) WriteCode(w http.ResponseWriter, r *http.Request, code int, e interface{}, opts ...EncoderOptions) {
+	if w, e, ok := h.transform(w, r, e); ok {
+		h.Writer.WriteCode(w, r, code, e, opts...)
+	}
+}
+
+// WriteCreated writes a response object to the ResponseWriter with status code 201 and
+// the Location header set to location.
+func (h *VersionedWriter) WriteCreated(w http.ResponseWriter, r *http.Request, location string, e interface{}) {
+	if w, e, ok := h.transform(w, r, e); ok {
+		h.Writer.WriteCreated(w, r, location, e)
+	}
+}
+
+// WriteError writes an error using the underlying Writer.
+func (h *VersionedWriter) WriteError(w http.ResponseWriter, r *http.Request, err error, opts ...Option) {
+	h.Writer.WriteError(w, r, err, opts...)
+}
+
+// WriteErrorCode writes an error using the underlying Writer and forces an error code.
+func (h *VersionedWriter) WriteErrorCode(w http.ResponseWriter, r *http.Request, code int, err error, opts ...Option) {
+	h.Writer.WriteErrorCode(w, r, code, err, opts...)
+}
+
+// versionedResponseWriter adds the version as media type parameter to the Content-Type header.
+type versionedResponseWriter struct {
+	http.ResponseWriter
+	parameter, version string
+	wroteHeader        bool
+}
+
+func (w *versionedResponseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if mediaType, params, err := mime.ParseMediaType(w.Header().Get("Content-Type")); err == nil && w.parameter != "" {
+			params[strings.ToLower(w.parameter)] = w.version
+			if ct := mime.FormatMediaType(mediaType, params); ct != "" {
+				w.Header().Set("Content-Type", ct)
+			}
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *versionedResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying ResponseWriter for use with http.ResponseController.
+func (w *versionedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
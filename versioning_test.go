@@ -0,0 +1,150 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package herodot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type userV2 struct {
+	Name struct {
+		First string `json:"first"`
+		Last  string `json:"last"`
+	} `json:"name"`
+}
+
+type userV1 struct {
+	Name string `json:"name"`
+}
+
+func TestVersionedWriter(t *testing.T) {
+	newWriter := func() *VersionedWriter {
+		h := NewVersionedWriter(NewNegotiationHandler(nil)).
+			Register("1", func(_ *http.Request, e interface{}) (interface{}, error) {
+				u, ok := e.(*userV2)
+				if !ok {
+					return nil, errors.New("unexpected type")
+				}
+				return &userV1{Name: u.Name.First + " " + u.Name.Last}, nil
+			}).
+			Register("2", nil)
+		h.Header = "Ory-Api-Version"
+		h.QueryParameter = "version"
+		return h
+	}
+	user := new(userV2)
+	user.Name.First, user.Name.Last = "Jane", "Doe"
+
+	for _, tc := range []struct {
+		name                string
+		url                 string
+		header              http.Header
+		expectedVersion     string
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "default",
+			url:                 "/",
+			expectedVersion:     "2",
+			expectedContentType: "application/json; charset=utf-8; version=2",
+			expectedBody:        `{"name":{"first":"Jane","last":"Doe"}}`,
+		},
+		{
+			name:                "media type parameter",
+			url:                 "/?version=2",
+			header:              http.Header{"Accept": {"application/json; version=1"}, "Ory-Api-Version": {"2"}},
+			expectedVersion:     "1",
+			expectedContentType: "application/json; charset=utf-8; version=1",
+			expectedBody:        `{"name":"Jane Doe"}`,
+		},
+		{
+			name:                "media type parameter of another media range",
+			url:                 "/",
+			header:              http.Header{"Accept": {"text/plain; version=1; q=0.5, application/json"}},
+			expectedVersion:     "2",
+			expectedContentType: "application/json; charset=utf-8; version=2",
+			expectedBody:        `{"name":{"first":"Jane","last":"Doe"}}`,
+		},
+		{
+			name:                "header",
+			url:                 "/?version=2",
+			header:              http.Header{"Ory-Api-Version": {"1"}},
+			expectedVersion:     "1",
+			expectedContentType: "application/json; charset=utf-8; version=1",
+			expectedBody:        `{"name":"Jane Doe"}`,
+		},
+		{
+			name:                "query parameter",
+			url:                 "/?version=1",
+			expectedVersion:     "1",
+			expectedContentType: "application/json; charset=utf-8; version=1",
+			expectedBody:        `{"name":"Jane Doe"}`,
+		},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.url, nil)
+			for k, v := range tc.header {
+				r.Header[k] = v
+			}
+			rec := httptest.NewRecorder()
+			newWriter().Write(rec, r, user)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tc.expectedVersion, rec.Header().Get("Ory-Api-Version"))
+			assert.Equal(t, tc.expectedContentType, rec.Header().Get("Content-Type"))
+			assert.Equal(t, []string{"Accept", "Ory-Api-Version"}, rec.Header().Values("Vary"))
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+
+	t.Run("case=unsupported version", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/?version=3", nil)
+		rec := httptest.NewRecorder()
+		newWriter().WriteCode(rec, r, http.StatusAccepted, user)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		var ec ErrorContainer
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&ec))
+		assert.Equal(t, "unsupported_version", ec.Error.ID())
+		assert.Equal(t, []interface{}{"1", "2"}, ec.Error.Details()["supported_versions"])
+	})
+
+	t.Run("case=media range of the wrapped writer", func(t *testing.T) {
+		h := NewVersionedWriter(NewJSONWriter(nil)).Register("1", nil).Register("2", nil)
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", "text/html;version=3, application/json;q=0.1")
+		rec := httptest.NewRecorder()
+		h.Write(rec, r, user)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json; charset=utf-8; version=2", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("case=transformer error", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/?version=1", nil)
+		rec := httptest.NewRecorder()
+		newWriter().Write(rec, r, "not a user")
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("case=default version", func(t *testing.T) {
+		h := newWriter()
+		h.DefaultVersion = "1"
+		rec := httptest.NewRecorder()
+		h.WriteCreated(rec, httptest.NewRequest("GET", "/", nil), "/users/1", user)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "/users/1", rec.Header().Get("Location"))
+		assert.JSONEq(t, `{"name":"Jane Doe"}`, rec.Body.String())
+	})
+}